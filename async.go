@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultAsyncBufferSize = 1024
+
+// AsyncHandler forwards every message to its handler from a background goroutine, so slow handlers (files, network)
+// don't stall the callers. When the buffer is full the callers block until there is room, unless DropWhenFull is set.
+// Call Close (or Flush) before the program exits to not lose the pending messages, Fatal flushes them by itself
+type AsyncHandler struct {
+	// dropped is first to keep it 64-bit aligned for atomic operations
+	dropped uint64
+
+	// DropWhenFull drops the new messages, instead of blocking, while the buffer is full
+	DropWhenFull bool
+
+	inner     Interface
+	queue     chan func()
+	finished  chan struct{}
+	lock      sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewAsyncHandler start forwarding to inner, bufferSize bounds the pending messages, 0 means the default of 1024
+func NewAsyncHandler(inner Interface, bufferSize int) *AsyncHandler {
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+
+	handler := &AsyncHandler{
+		inner:    inner,
+		queue:    make(chan func(), bufferSize),
+		finished: make(chan struct{}),
+	}
+
+	go handler.run()
+
+	return handler
+}
+
+func (handler *AsyncHandler) Init(namespace string, level Level) {
+	if initHandler, ok := handler.inner.(InitInterface); ok {
+		handler.enqueue(func() {
+			initHandler.Init(namespace, level)
+		})
+	}
+}
+
+func (handler *AsyncHandler) Log(record Record) {
+	handler.enqueue(func() {
+		dispatchTo(handler.inner, record)
+	})
+}
+
+func (handler *AsyncHandler) Debug(msg string) {
+	if debugHandler, ok := handler.inner.(DebugInterface); ok {
+		handler.enqueue(func() {
+			debugHandler.Debug(msg)
+		})
+	}
+}
+
+func (handler *AsyncHandler) Info(msg string) {
+	if infoHandler, ok := handler.inner.(InfoInterface); ok {
+		handler.enqueue(func() {
+			infoHandler.Info(msg)
+		})
+	}
+}
+
+func (handler *AsyncHandler) Warn(msg string) {
+	if warnHandler, ok := handler.inner.(WarnInterface); ok {
+		handler.enqueue(func() {
+			warnHandler.Warn(msg)
+		})
+	}
+}
+
+func (handler *AsyncHandler) Error(msg string) {
+	if errorHandler, ok := handler.inner.(ErrorInterface); ok {
+		handler.enqueue(func() {
+			errorHandler.Error(msg)
+		})
+	}
+}
+
+// Fatal flush the pending messages and forward the fatal one right away, the process is about to exit
+func (handler *AsyncHandler) Fatal(msg string) {
+	handler.Flush()
+	if fatalHandler, ok := handler.inner.(FatalInterface); ok {
+		fatalHandler.Fatal(msg)
+	}
+}
+
+func (handler *AsyncHandler) FatalFields(msg string, fields map[string]interface{}) {
+	handler.Flush()
+	if fatalHandler, ok := handler.inner.(FatalFieldsInterface); ok {
+		fatalHandler.FatalFields(msg, fields)
+	} else if fatalHandler, ok := handler.inner.(FatalInterface); ok {
+		fatalHandler.Fatal(msg)
+	}
+}
+
+// Flush wait until every message enqueued before it was forwarded
+func (handler *AsyncHandler) Flush() {
+	done := make(chan struct{})
+
+	handler.lock.RLock()
+	if handler.closed {
+		handler.lock.RUnlock()
+		return
+	}
+	handler.queue <- func() {
+		close(done)
+	}
+	handler.lock.RUnlock()
+
+	<-done
+}
+
+// Close forward the pending messages, stop the goroutine and close the handler if it implements io.Closer. Messages
+// received afterwards are dropped
+func (handler *AsyncHandler) Close() error {
+	handler.closeOnce.Do(func() {
+		handler.lock.Lock()
+		handler.closed = true
+		close(handler.queue)
+		handler.lock.Unlock()
+
+		<-handler.finished
+
+		if closer, ok := handler.inner.(io.Closer); ok {
+			handler.closeErr = closer.Close()
+		}
+	})
+
+	return handler.closeErr
+}
+
+// Dropped it's the number of messages lost because the buffer was full or the handler was closed
+func (handler *AsyncHandler) Dropped() uint64 {
+	return atomic.LoadUint64(&handler.dropped)
+}
+
+// Metrics it's the metrics of the handler, if it implements MetricsInterface, plus the messages dropped here
+func (handler *AsyncHandler) Metrics() HandlerMetrics {
+	var metrics HandlerMetrics
+	if metricsHandler, ok := handler.inner.(MetricsInterface); ok {
+		metrics = metricsHandler.Metrics()
+	}
+	metrics.Dropped += handler.Dropped()
+
+	return metrics
+}
+
+func (handler *AsyncHandler) enqueue(call func()) {
+	handler.lock.RLock()
+	defer handler.lock.RUnlock()
+
+	if handler.closed {
+		atomic.AddUint64(&handler.dropped, 1)
+		return
+	}
+
+	if !handler.DropWhenFull {
+		handler.queue <- call
+		return
+	}
+
+	select {
+	case handler.queue <- call:
+	default:
+		atomic.AddUint64(&handler.dropped, 1)
+	}
+}
+
+func (handler *AsyncHandler) run() {
+	defer close(handler.finished)
+
+	for call := range handler.queue {
+		call()
+	}
+}
+
+// asyncDispatch it's shared by a logger and the loggers derived from it
+type asyncDispatch struct {
+	lock    sync.Mutex
+	handler *AsyncHandler
+}
+
+// SetAsync dispatch the messages of logger, and of the loggers derived from it afterwards, from a background goroutine
+// through a buffer of bufferSize messages (0 means 1024). When the buffer is full the callers wait for room, unless
+// dropWhenFull is set. Call Flush or Close before the program exits to not lose the pending messages, Fatal flushes
+// them by itself
+func (logger *Logger) SetAsync(bufferSize int, dropWhenFull bool) {
+	handler := NewAsyncHandler(nil, bufferSize)
+	handler.DropWhenFull = dropWhenFull
+
+	logger.handlersLock.Lock()
+	if logger.async == nil {
+		logger.async = &asyncDispatch{}
+	}
+	async := logger.async
+	logger.handlersLock.Unlock()
+
+	async.lock.Lock()
+	previous := async.handler
+	async.handler = handler
+	async.lock.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+}
+
+// Flush wait until the messages logged before it reached the handlers, it does nothing when logger isn't async
+func (logger *Logger) Flush() {
+	if handler := logger.asyncHandler(); handler != nil {
+		handler.Flush()
+	}
+}
+
+// Close flush the pending messages and go back to dispatching synchronously, the handlers aren't closed
+func (logger *Logger) Close() error {
+	logger.handlersLock.Lock()
+	async := logger.async
+	logger.handlersLock.Unlock()
+
+	if async == nil {
+		return nil
+	}
+
+	async.lock.Lock()
+	handler := async.handler
+	async.handler = nil
+	async.lock.Unlock()
+
+	if handler == nil {
+		return nil
+	}
+
+	return handler.Close()
+}
+
+func (logger *Logger) asyncHandler() *AsyncHandler {
+	logger.handlersLock.Lock()
+	async := logger.async
+	logger.handlersLock.Unlock()
+
+	if async == nil {
+		return nil
+	}
+
+	async.lock.Lock()
+	defer async.lock.Unlock()
+
+	return async.handler
+}
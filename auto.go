@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// autoNamespaces caches the logger of each AutoNamespace call site
+var autoNamespaces sync.Map
+
+// AutoNamespace return the logger of the caller's package, the namespace is the import path with "/" replaced by
+// "." (e.g. "github.com.org.app.db"). Resolving the caller costs a runtime.Caller on every call plus a
+// runtime.FuncForPC the first time for each call site, so prefer storing the result in a package variable
+func AutoNamespace() *Logger {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return DefaultLogger
+	}
+
+	if logger, ok := autoNamespaces.Load(pc); ok {
+		return logger.(*Logger)
+	}
+
+	logger := Namespace(packageNamespace(pc))
+	autoNamespaces.Store(pc, logger)
+
+	return logger
+}
+
+// packageNamespace the namespace of the package of the function at pc
+func packageNamespace(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+
+	return strings.Replace(packagePath(fn.Name()), "/", ".", -1)
+}
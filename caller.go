@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// callerField it's the field holding the call site of messages of loggers returned by WithCaller
+const callerField = "caller"
+
+// thisPackage it's the import path of this package, its frames are skipped when looking for the call site
+var thisPackage = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	return packagePath(runtime.FuncForPC(pc).Name())
+}()
+
+// WithCaller return a copy of the logger that reports the file and line of the call site, as the "caller" field for
+// handlers that support fields and prefixed to the message for the others. It's off by default because
+// runtime.Callers isn't free
+func (logger *Logger) WithCaller() *Logger {
+	derived := logger.derive()
+	derived.reportCaller = 1
+
+	return derived
+}
+
+// WithCaller ...
+func WithCaller() *Logger {
+	return DefaultLogger.WithCaller()
+}
+
+// SetReportCaller same as WithCaller, but turning the call site on or off for logger itself
+func (logger *Logger) SetReportCaller(enabled bool) {
+	var report int32
+	if enabled {
+		report = 1
+	}
+
+	atomic.StoreInt32(&logger.reportCaller, report)
+}
+
+// SetReportCaller ...
+func SetReportCaller(enabled bool) {
+	DefaultLogger.SetReportCaller(enabled)
+}
+
+// callerFrame return the first frame outside this package and the standard log packages, so the package-level
+// functions, loggers used as io.Writer behind a *log.Logger and slog report the user's call site
+func callerFrame() runtime.Frame {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if pkg := packagePath(frame.Function); pkg != thisPackage && pkg != "log" && pkg != "log/slog" {
+			return frame
+		}
+		if !more {
+			return runtime.Frame{}
+		}
+	}
+}
+
+// setCaller fill the call site of record from frame
+func (record *Record) setCaller(frame runtime.Frame) {
+	if frame.File == "" {
+		return
+	}
+
+	record.File, record.Line, record.Function = frame.File, frame.Line, frame.Function
+	record.Caller = filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+	record.Fields = withCaller(record.Fields, record.Caller)
+}
+
+// withCaller return the fields of record with the call site added, the fields of the logger are never changed in
+// place
+func withCaller(fields map[string]interface{}, caller string) map[string]interface{} {
+	merged := make(map[string]interface{}, len(fields)+1)
+	for key, value := range fields {
+		merged[key] = value
+	}
+	merged[callerField] = caller
+
+	return merged
+}
+
+// packagePath extract the package path from a function name like "github.com/org/app/db.(*Pool).Get"
+func packagePath(name string) string {
+	lastSlash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[lastSlash+1:], "."); dot >= 0 {
+		name = name[:lastSlash+1+dot]
+	}
+
+	return name
+}
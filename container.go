@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+type (
+	// ContainerHandler writes records using the schema of the docker json-file log driver
+	// ({"log": ..., "stream": ..., "time": ...}), Debug and Info are tagged as stdout and Warn and above as stderr
+	ContainerHandler struct {
+		// Output defaults to os.Stdout
+		Output io.Writer
+
+		namespace string
+		lock      sync.Mutex
+	}
+
+	containerEntry struct {
+		Log    string `json:"log"`
+		Stream string `json:"stream"`
+		Time   string `json:"time"`
+	}
+)
+
+// NewContainerHandler ...
+func NewContainerHandler(w io.Writer) *ContainerHandler {
+	return &ContainerHandler{Output: w}
+}
+
+func (handler *ContainerHandler) Init(namespace string, level Level) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	if namespace != "" {
+		namespace = "<" + namespace + "> "
+	}
+	handler.namespace = namespace
+}
+
+func (handler *ContainerHandler) write(stream, label, msg string) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	b, err := json.Marshal(&containerEntry{
+		Log:    handler.namespace + label + msg + "\n",
+		Stream: stream,
+		Time:   now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return
+	}
+
+	output := handler.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	output.Write(append(b, '\n'))
+}
+
+func (handler *ContainerHandler) Debug(msg string) {
+	handler.write("stdout", "[DEBUG] ", msg)
+}
+
+func (handler *ContainerHandler) Info(msg string) {
+	handler.write("stdout", "[INFO] ", msg)
+}
+
+func (handler *ContainerHandler) Warn(msg string) {
+	handler.write("stderr", "[WARN] ", msg)
+}
+
+func (handler *ContainerHandler) Error(msg string) {
+	handler.write("stderr", "[ERROR] ", msg)
+}
+
+func (handler *ContainerHandler) Fatal(msg string) {
+	handler.write("stderr", "[FATAL] ", msg)
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestContainerHandlerWritesDockerSchema(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewContainerHandler(&buf)
+	handler.Init("container", LevelInfo)
+
+	handler.Warn("disk almost full")
+
+	var entry map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["log"] != "<container> [WARN] disk almost full\n" {
+		t.Fatalf("unexpected log %q", entry["log"])
+	}
+	if entry["stream"] != "stderr" {
+		t.Fatal("expected stderr stream, got", entry["stream"])
+	}
+	if _, err := time.Parse(time.RFC3339Nano, entry["time"]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContainerHandlerIsStableOnTestMode(t *testing.T) {
+	TestMode = true
+	defer func() { TestMode = false }()
+
+	var buf bytes.Buffer
+	handler := NewContainerHandler(&buf)
+	handler.Init("", LevelInfo)
+	handler.Info("golden")
+
+	expected := `{"log":"[INFO] golden\n","stream":"stdout","time":"2000-01-01T00:00:00Z"}` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
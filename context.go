@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+type contextKey int
+
+const (
+	levelContextKey contextKey = iota
+	loggerContextKey
+)
+
+// ContextWithLevel return a copy of ctx that overrides the level of the Ctx log methods, e.g. to log at debug the calls
+// of a sampled request while the rest of the app stays at info
+func ContextWithLevel(ctx context.Context, level Level) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, levelContextKey, level)
+}
+
+// NewContext return a copy of ctx carrying logger, e.g. a request-scoped logger attached by a middleware, for
+// FromContext and the package-level Ctx functions
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// FromContext return the logger carried by ctx, or DefaultLogger when there is none
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey).(*Logger); ok && logger != nil {
+			return logger
+		}
+	}
+
+	return DefaultLogger
+}
+
+var contextExtractors []func(context.Context) map[string]interface{}
+var contextExtractorsLock sync.Mutex
+
+// RegisterContextExtractor register a function that pulls fields (request IDs, trace IDs, user IDs...) out of a
+// context for WithContext and the Ctx log methods. Extractors run in registration order and the later ones win on
+// key collisions
+func RegisterContextExtractor(extractor func(context.Context) map[string]interface{}) {
+	contextExtractorsLock.Lock()
+	defer contextExtractorsLock.Unlock()
+
+	contextExtractors = append(contextExtractors, extractor)
+}
+
+// contextFields return the fields of all extractors, a nil context has no fields
+func contextFields(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	contextExtractorsLock.Lock()
+	extractors := contextExtractors
+	contextExtractorsLock.Unlock()
+
+	var fields map[string]interface{}
+	for _, extractor := range extractors {
+		for key, value := range extractor(ctx) {
+			if fields == nil {
+				fields = map[string]interface{}{}
+			}
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
+
+// WithContext return a logger with the fields extracted from ctx, see WithFields
+func (logger *Logger) WithContext(ctx context.Context) *Logger {
+	return logger.WithFields(contextFields(ctx))
+}
+
+// withContext same as WithContext, but it doesn't derive a logger when ctx has no fields
+func (logger *Logger) withContext(ctx context.Context) *Logger {
+	fields := contextFields(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+
+	return logger.WithFields(fields)
+}
+
+// levelFor return the level overridden by ctx, or the logger level when there is no override
+func (logger *Logger) levelFor(ctx context.Context) Level {
+	if ctx != nil {
+		if level, ok := ctx.Value(levelContextKey).(Level); ok {
+			return level
+		}
+	}
+
+	return logger.GetLevel()
+}
+
+// DebugCtx ...
+func (logger *Logger) DebugCtx(ctx context.Context, format string, v ...interface{}) {
+	logger.withContext(ctx).log(logger.levelFor(ctx), LevelDebug, format, v...)
+}
+
+// InfoCtx ...
+func (logger *Logger) InfoCtx(ctx context.Context, format string, v ...interface{}) {
+	logger.withContext(ctx).log(logger.levelFor(ctx), LevelInfo, format, v...)
+}
+
+// WarnCtx ...
+func (logger *Logger) WarnCtx(ctx context.Context, format string, v ...interface{}) {
+	logger.withContext(ctx).log(logger.levelFor(ctx), LevelWarn, format, v...)
+}
+
+// ErrorCtx ...
+func (logger *Logger) ErrorCtx(ctx context.Context, format string, v ...interface{}) {
+	logger.withContext(ctx).log(logger.levelFor(ctx), LevelError, format, v...)
+}
+
+// WithContext it's FromContext(ctx).WithContext(ctx)
+func WithContext(ctx context.Context) *Logger {
+	return FromContext(ctx).WithContext(ctx)
+}
+
+// DebugCtx log with the logger carried by ctx, or DefaultLogger when there is none
+func DebugCtx(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).DebugCtx(ctx, format, v...)
+}
+
+// InfoCtx ...
+func InfoCtx(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).InfoCtx(ctx, format, v...)
+}
+
+// WarnCtx ...
+func WarnCtx(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).WarnCtx(ctx, format, v...)
+}
+
+// ErrorCtx ...
+func ErrorCtx(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).ErrorCtx(ctx, format, v...)
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// getEnvVarFilterName it's the variable holding levels by namespace pattern, e.g.
+// SEVERINO_LOGGER__FILTER="db.*=debug,http=warn,*=error", the double underscore keeps it apart from the level variable
+// of a namespace called "filter"
+func getEnvVarFilterName() string {
+	return defaultEnvironmentVariablePrefix + "__FILTER"
+}
+
+// getEnvFilterLevel return the level of the first pattern of the filter variable that matches namespace, patterns are
+// globs as accepted by path.Match and both sides are compared in lower case
+func getEnvFilterLevel(namespace string) string {
+	filter := os.Getenv(getEnvVarFilterName())
+	if filter == "" {
+		return ""
+	}
+
+	namespace = strings.ToLower(namespace)
+	for _, rule := range strings.Split(filter, ",") {
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		pattern := strings.ToLower(strings.TrimSpace(parts[0]))
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ErrorField it's the field holding the message of the error given to WithError
+	ErrorField = "error"
+	// ErrorChainField it's the field holding the messages of the errors wrapped by it, outermost first
+	ErrorChainField = "error_chain"
+	// StackField it's the field holding the stack trace of the WithError call site, see ErrorStacks
+	StackField = "stack"
+)
+
+// ErrorStacks makes WithError and ErrorErr capture the stack trace of their call site, it's off by default because
+// capturing a stack isn't free
+var ErrorStacks = false
+
+// WithError return a logger that attaches err as fields: its message, the messages of the errors it wraps (following
+// Unwrap, including the ones joined by errors.Join) when there are any, and the stack trace when ErrorStacks is set. A
+// nil err returns logger itself
+func (logger *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return logger
+	}
+
+	fields := map[string]interface{}{ErrorField: err.Error()}
+	if chain := errorChain(err); len(chain) > 1 {
+		fields[ErrorChainField] = chain
+	}
+	if ErrorStacks {
+		fields[StackField] = callerStack()
+	}
+
+	return logger.WithFields(fields)
+}
+
+// ErrorErr log an error message with err attached as fields, see WithError
+func (logger *Logger) ErrorErr(err error, format string, v ...interface{}) {
+	logger.WithError(err).log(logger.GetLevel(), LevelError, format, v...)
+}
+
+// WithError ...
+func WithError(err error) *Logger {
+	return DefaultLogger.WithError(err)
+}
+
+// ErrorErr ...
+func ErrorErr(err error, format string, v ...interface{}) {
+	DefaultLogger.ErrorErr(err, format, v...)
+}
+
+// maxErrorChain bounds errorChain, so an error that unwraps to itself or a cycle doesn't loop forever
+const maxErrorChain = 32
+
+// errorChain return the messages of err and of every error it wraps, depth first, up to maxErrorChain of them
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(err error) {
+		for err != nil && len(chain) < maxErrorChain {
+			chain = append(chain, err.Error())
+
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, inner := range joined.Unwrap() {
+					walk(inner)
+				}
+				return
+			}
+			wrapper, ok := err.(interface{ Unwrap() error })
+			if !ok {
+				return
+			}
+			err = wrapper.Unwrap()
+		}
+	}
+	walk(err)
+
+	return chain
+}
+
+// callerStack return the stack from the first frame outside this package, one "function file:line" per line
+func callerStack() string {
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if packagePath(frame.Function) != thisPackage {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(frame.Function + " " + frame.File + ":" + strconv.Itoa(frame.Line))
+		}
+		if !more {
+			return b.String()
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+type (
+	// FanoutHandler dispatch every message to all of its handlers, each one formatting it its own way, e.g. text to
+	// the console and JSON to a file
+	FanoutHandler struct {
+		handlers []Interface
+	}
+
+	// handlerErrors it's the errors of several handlers as one
+	handlerErrors []error
+)
+
+// NewFanoutHandler ...
+func NewFanoutHandler(handlers ...Interface) *FanoutHandler {
+	return &FanoutHandler{handlers: handlers}
+}
+
+// NewDualHandler return a FanoutHandler writing JSON to stdout, for a collector, and text to stderr, for the operator
+// watching the console. The text only gets the messages at level or more severe, LevelDebug gives it every record
+func NewDualHandler(level Level) *FanoutHandler {
+	return NewFanoutHandler(NewJSONHandler(os.Stdout), &levelHandler{handler: &DefaultHandler{Output: os.Stderr}, level: level})
+}
+
+func (handler *FanoutHandler) Init(namespace string, level Level) {
+	for _, h := range handler.handlers {
+		if initHandler, ok := h.(InitInterface); ok {
+			initHandler.Init(namespace, level)
+		}
+	}
+}
+
+func (handler *FanoutHandler) Log(record Record) {
+	for _, h := range handler.handlers {
+		dispatchTo(h, record)
+	}
+}
+
+func (handler *FanoutHandler) Debug(msg string) {
+	for _, h := range handler.handlers {
+		if debugHandler, ok := h.(DebugInterface); ok {
+			debugHandler.Debug(msg)
+		}
+	}
+}
+
+func (handler *FanoutHandler) Info(msg string) {
+	for _, h := range handler.handlers {
+		if infoHandler, ok := h.(InfoInterface); ok {
+			infoHandler.Info(msg)
+		}
+	}
+}
+
+func (handler *FanoutHandler) Warn(msg string) {
+	for _, h := range handler.handlers {
+		if warnHandler, ok := h.(WarnInterface); ok {
+			warnHandler.Warn(msg)
+		}
+	}
+}
+
+func (handler *FanoutHandler) Error(msg string) {
+	for _, h := range handler.handlers {
+		if errorHandler, ok := h.(ErrorInterface); ok {
+			errorHandler.Error(msg)
+		}
+	}
+}
+
+func (handler *FanoutHandler) Fatal(msg string) {
+	for _, h := range handler.handlers {
+		if fatalHandler, ok := h.(FatalInterface); ok {
+			fatalHandler.Fatal(msg)
+		}
+	}
+}
+
+// Metrics sum the metrics of the handlers that implement MetricsInterface
+func (handler *FanoutHandler) Metrics() HandlerMetrics {
+	var total HandlerMetrics
+	for _, h := range handler.handlers {
+		if metricsHandler, ok := h.(MetricsInterface); ok {
+			total.Add(metricsHandler.Metrics())
+		}
+	}
+
+	return total
+}
+
+// Close close every handler that implements io.Closer, returning all of their errors
+func (handler *FanoutHandler) Close() error {
+	var errs handlerErrors
+	for _, h := range handler.handlers {
+		if closer, ok := h.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func (errs handlerErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
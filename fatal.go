@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// ExitFunc it's called by Fatal with the exit code after the handlers and the fatal hooks, tests can replace it to
+// catch fatal paths without exiting
+var ExitFunc = os.Exit
+
+// FatalHookTimeout bounds how long Fatal waits for the fatal hooks, together, before exiting
+var FatalHookTimeout = 5 * time.Second
+
+var fatalHooks []func(Record)
+var fatalHooksLock sync.Mutex
+
+// RegisterFatalHook register a function called by Fatal right before the process exits, after every handler got the
+// fatal message. Hooks run in the order they were registered, a panic in a hook is recovered and the next hook
+// runs, and no hook can prevent the exit
+func RegisterFatalHook(hook func(Record)) {
+	fatalHooksLock.Lock()
+	defer fatalHooksLock.Unlock()
+
+	fatalHooks = append(fatalHooks, hook)
+}
+
+// OnFatal register a fatal hook that doesn't need the record, e.g. to flush a buffered handler
+func OnFatal(hook func()) {
+	RegisterFatalHook(func(Record) {
+		hook()
+	})
+}
+
+// runFatalHooks gives up on the hooks still running after FatalHookTimeout
+func runFatalHooks(record Record) {
+	fatalHooksLock.Lock()
+	hooks := make([]func(Record), len(fatalHooks))
+	copy(hooks, fatalHooks)
+	fatalHooksLock.Unlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, hook := range hooks {
+			runFatalHook(hook, record)
+		}
+	}()
+
+	timer := time.NewTimer(FatalHookTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
+
+func runFatalHook(hook func(Record), record Record) {
+	defer func() {
+		recover()
+	}()
+
+	hook(record)
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFatalHooksRunInOrderAndRecoverPanics(t *testing.T) {
+	var calls []string
+	RegisterFatalHook(func(record Record) {
+		calls = append(calls, "first:"+record.Message)
+		panic("broken hook")
+	})
+	RegisterFatalHook(func(record Record) {
+		calls = append(calls, "second:"+record.Namespace)
+	})
+	defer func() { fatalHooks = nil }()
+
+	runFatalHooks(Record{Namespace: "db", Message: "connection lost"})
+
+	if len(calls) != 2 || calls[0] != "first:connection lost" || calls[1] != "second:db" {
+		t.Fatal("unexpected hook calls", calls)
+	}
+}
+
+func TestFatalCallsExitFuncAfterHooks(t *testing.T) {
+	var out bytes.Buffer
+	log := NewLogger("fatal-exit", LevelDebug, NewJSONHandler(&out))
+
+	var flushed bool
+	OnFatal(func() { flushed = true })
+	defer func() { fatalHooks = nil }()
+
+	var code int
+	defer func(exit func(int)) { ExitFunc = exit }(ExitFunc)
+	ExitFunc = func(c int) { code = c }
+
+	log.Fatal("bye")
+
+	if code != 1 || !flushed || !strings.Contains(out.String(), `"msg":"bye"`) {
+		t.Fatal("unexpected fatal", code, flushed, out.String())
+	}
+}
+
+func TestFatalHooksTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	OnFatal(func() { <-block })
+	defer func() { fatalHooks = nil }()
+
+	defer func(timeout time.Duration) { FatalHookTimeout = timeout }(FatalHookTimeout)
+	FatalHookTimeout = 10 * time.Millisecond
+
+	start := time.Now()
+	runFatalHooks(Record{})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatal("expected hooks to time out, took", elapsed)
+	}
+}
+
+func TestSetExitFuncAndPanic(t *testing.T) {
+	memory := NewMemoryHandler()
+	log := NewLogger("fatal-panic", LevelFatal, memory)
+
+	var code int
+	log.SetExitFunc(func(c int) { code = c })
+	log.Error("discarded")
+	log.With("job", 7).Fatal("bye")
+	if code != 1 {
+		t.Fatal("expected the exit func of the logger to be inherited, got", code)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "gone" {
+				t.Fatal("expected a panic with the message, got", r)
+			}
+		}()
+		log.Panic("gone")
+	}()
+
+	entries := memory.Entries()
+	if len(entries) != 2 || entries[0].Level != LevelFatal || entries[1].Msg != "gone" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import "sync/atomic"
+
+type (
+	// DebugFieldsInterface handlers get the fields of the logger with the message, they must not change the map
+	DebugFieldsInterface interface {
+		DebugFields(msg string, fields map[string]interface{})
+	}
+	// InfoFieldsInterface ...
+	InfoFieldsInterface interface {
+		InfoFields(msg string, fields map[string]interface{})
+	}
+	// WarnFieldsInterface ...
+	WarnFieldsInterface interface {
+		WarnFields(msg string, fields map[string]interface{})
+	}
+	// ErrorFieldsInterface ...
+	ErrorFieldsInterface interface {
+		ErrorFields(msg string, fields map[string]interface{})
+	}
+	// FatalFieldsInterface ...
+	FatalFieldsInterface interface {
+		FatalFields(msg string, fields map[string]interface{})
+	}
+)
+
+// WithFields return a logger that attaches fields to every message, merged with the fields logger already has (the
+// new ones win on collision). The returned logger shares the level and handlers of logger, so later changes to them
+// apply to both, and it isn't registered as a namespace
+func (logger *Logger) WithFields(fields map[string]interface{}) *Logger {
+	merged := make(map[string]interface{}, len(logger.fields)+len(fields))
+	for key, value := range logger.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	derived := logger.derive()
+	derived.fields = merged
+
+	return derived
+}
+
+// With same as WithFields for a single field
+func (logger *Logger) With(key string, value interface{}) *Logger {
+	return logger.WithFields(map[string]interface{}{key: value})
+}
+
+// derive return an unregistered copy of logger that shares its level, handlers and counters
+func (logger *Logger) derive() *Logger {
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	// the slices are shared, they are replaced, never changed in place
+	return &Logger{
+		Namespace:          logger.Namespace,
+		level:              logger.level,
+		handlers:           logger.handlers,
+		counters:           logger.counters,
+		ConcurrentDispatch: logger.ConcurrentDispatch,
+		fields:             logger.fields,
+		limiter:            logger.limiter,
+		sampler:            logger.sampler,
+		reportCaller:       atomic.LoadInt32(&logger.reportCaller),
+		exitFunc:           logger.exitFunc,
+		async:              logger.async,
+		filters:            logger.filters,
+		hooks:              logger.hooks,
+		monitors:           logger.monitors,
+		explicitLevel:      true,
+	}
+}
+
+// WithFields ...
+func WithFields(fields map[string]interface{}) *Logger {
+	return DefaultLogger.WithFields(fields)
+}
+
+// With ...
+func With(key string, value interface{}) *Logger {
+	return DefaultLogger.With(key, value)
+}
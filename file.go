@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat it's the suffix of rotated files, it sorts in the same order as the time
+const backupTimeFormat = "20060102T150405.000000000"
+
+type (
+	// FileHandler writes like DefaultHandler to a file that is rotated by size and/or age. The rotated files are
+	// renamed to "<path>.<timestamp>", optionally gzipped, and only the newest MaxBackups are kept
+	FileHandler struct {
+		DefaultHandler
+
+		// MaxSize rotates the file before it grows past MaxSize bytes, 0 disables it
+		MaxSize int64
+		// MaxAge rotates the file once it was opened for longer than MaxAge, 0 disables it
+		MaxAge time.Duration
+		// MaxBackups it's how many rotated files are kept, 0 keeps all of them
+		MaxBackups int
+		// Compress gzip the rotated files, in the background
+		Compress bool
+
+		file *rotatingFile
+	}
+
+	// rotatingFile it's the output of a FileHandler
+	rotatingFile struct {
+		handler *FileHandler
+		path    string
+
+		lock   sync.Mutex
+		file   *os.File
+		size   int64
+		opened time.Time
+
+		// cleanup serializes compressing and removing the rotated files
+		cleanup sync.Mutex
+		pending sync.WaitGroup
+	}
+)
+
+// NewFileHandler open path for appending, creating it when needed
+func NewFileHandler(path string) (*FileHandler, error) {
+	handler := &FileHandler{}
+	handler.file = &rotatingFile{handler: handler, path: path}
+	if err := handler.file.open(); err != nil {
+		return nil, err
+	}
+	handler.Output = handler.file
+
+	return handler, nil
+}
+
+// Rotate the file now, e.g. from a signal sent by an external tool
+func (handler *FileHandler) Rotate() error {
+	handler.file.lock.Lock()
+	defer handler.file.lock.Unlock()
+
+	return handler.file.rotate()
+}
+
+// RotateOnSignal rotate the file whenever sig arrives (usually syscall.SIGHUP), call the returned function to stop
+func (handler *FileHandler) RotateOnSignal(sig os.Signal) (stop func()) {
+	return onSignal(sig, func() {
+		handler.Rotate()
+	})
+}
+
+// Close the file, after compressing the rotated ones still pending
+func (handler *FileHandler) Close() error {
+	handler.file.lock.Lock()
+	defer handler.file.lock.Unlock()
+
+	handler.file.pending.Wait()
+	if handler.file.file == nil {
+		return nil
+	}
+
+	err := handler.file.file.Close()
+	handler.file.file = nil
+
+	return err
+}
+
+func (file *rotatingFile) Write(b []byte) (int, error) {
+	file.lock.Lock()
+	defer file.lock.Unlock()
+
+	if file.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	maxSize := file.handler.MaxSize
+	maxAge := file.handler.MaxAge
+	if (maxSize > 0 && file.size > 0 && file.size+int64(len(b)) > maxSize) ||
+		(maxAge > 0 && time.Since(file.opened) > maxAge) {
+		if err := file.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := file.file.Write(b)
+	file.size += int64(n)
+
+	return n, err
+}
+
+func (file *rotatingFile) open() error {
+	f, err := os.OpenFile(file.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	file.file = f
+	file.size = info.Size()
+	file.opened = time.Now()
+
+	return nil
+}
+
+// rotate must be called with the lock held
+func (file *rotatingFile) rotate() error {
+	if file.file == nil {
+		return os.ErrClosed
+	}
+	if err := file.file.Close(); err != nil {
+		return err
+	}
+	file.file = nil
+
+	backup := file.path + "." + time.Now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(file.path, backup); err != nil {
+		// keep writing to the same file rather than losing messages
+		file.open()
+		return err
+	}
+	if err := file.open(); err != nil {
+		return err
+	}
+
+	file.pending.Add(1)
+	go func() {
+		defer file.pending.Done()
+
+		file.cleanup.Lock()
+		defer file.cleanup.Unlock()
+
+		if file.handler.Compress {
+			compressFile(backup)
+		}
+		file.removeOldBackups()
+	}()
+
+	return nil
+}
+
+// removeOldBackups keep the newest MaxBackups rotated files
+func (file *rotatingFile) removeOldBackups() {
+	maxBackups := file.handler.MaxBackups
+	if maxBackups <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(file.path)
+	prefix := filepath.Base(file.path) + "."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasPrefix(name, prefix) && !entry.IsDir() {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > maxBackups {
+		os.Remove(filepath.Join(dir, backups[0]))
+		backups = backups[1:]
+	}
+}
+
+// compressFile replace path by path.gz, keeping path when anything fails
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileHandlerRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	handler, err := NewFileHandler(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.MaxSize = 30
+	handler.MaxBackups = 2
+	handler.Compress = true
+
+	log := NewLogger("file", LevelInfo)
+	log.AddHandler(handler)
+	for i := 0; i < 5; i++ {
+		log.Info("message %d", i)
+	}
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	current, _ := os.ReadFile(path)
+	if string(current) != "<file> [INFO] message 4\n" {
+		t.Fatalf("unexpected current file %q", current)
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Fatal("expected 2 backups, got", backups)
+	}
+	for _, backup := range backups {
+		if !strings.HasSuffix(backup, ".gz") {
+			t.Fatal("expected compressed backups, got", backup)
+		}
+	}
+
+	f, _ := os.Open(backups[1])
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(gz)
+	if string(content) != "<file> [INFO] message 3\n" {
+		t.Fatalf("unexpected newest backup %q", content)
+	}
+}
+
+func TestFileHandlerRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	handler, err := NewFileHandler(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	handler.Init("", LevelInfo)
+	handler.Info("before")
+	if err := handler.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	handler.Info("after")
+
+	current, _ := os.ReadFile(path)
+	backups, _ := filepath.Glob(path + ".*")
+	if string(current) != "[INFO] after\n" || len(backups) != 1 {
+		t.Fatalf("unexpected rotation %q %v", current, backups)
+	}
+}
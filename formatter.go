@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Formatter renders a record as a single line, without the trailing newline, for DefaultHandler
+	Formatter interface {
+		Format(record Record) string
+	}
+
+	// TextFormatter renders like DefaultHandler does by default, "<namespace> [LEVEL] message key=value", with the
+	// parts configurable
+	TextFormatter struct {
+		// TimeLayout prefixes the line with the time of the record in this layout (e.g. time.RFC3339), empty leaves
+		// the time out
+		TimeLayout string
+		// HideNamespace and HideLevel leave out "<namespace>" and "[LEVEL]"
+		HideNamespace bool
+		HideLevel     bool
+		// PID adds "[pid]" after the time
+		PID bool
+		// FieldOrder lists the fields rendered first, in this order, the rest follow sorted by key
+		FieldOrder []string
+		// Color paints the level with ANSI colors, meant for terminals (see IsTerminal)
+		Color bool
+	}
+)
+
+var levelColors = map[string]string{
+	"DEBUG": "\x1b[90m",
+	"INFO":  "\x1b[36m",
+	"WARN":  "\x1b[33m",
+	"ERROR": "\x1b[31m",
+	"FATAL": "\x1b[35m",
+}
+
+const colorReset = "\x1b[0m"
+
+// IsTerminal tell whether w is a terminal, e.g. to set TextFormatter.Color only when writing to one
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (formatter *TextFormatter) Format(record Record) string {
+	var b strings.Builder
+
+	if formatter.TimeLayout != "" {
+		b.WriteString(record.Time.Format(formatter.TimeLayout) + " ")
+	}
+	if formatter.PID {
+		b.WriteString("[" + strconv.Itoa(os.Getpid()) + "] ")
+	}
+	if !formatter.HideNamespace {
+		b.WriteString(namespacePrefix(record.Namespace))
+	}
+	if !formatter.HideLevel {
+		label := levelLabel(record)
+		if color, ok := levelColors[label]; ok && formatter.Color {
+			b.WriteString(color + "[" + label + "]" + colorReset + " ")
+		} else {
+			b.WriteString("[" + label + "] ")
+		}
+	}
+
+	b.WriteString(record.Message)
+	b.WriteString(formatter.fields(record.Fields))
+
+	return b.String()
+}
+
+func (formatter *TextFormatter) fields(fields map[string]interface{}) string {
+	if len(formatter.FieldOrder) == 0 {
+		return formatFields(fields)
+	}
+
+	var b strings.Builder
+	rest := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		rest[key] = value
+	}
+	for _, key := range formatter.FieldOrder {
+		if value, ok := rest[key]; ok {
+			b.WriteString(formatFields(map[string]interface{}{key: value}))
+			delete(rest, key)
+		}
+	}
+	b.WriteString(formatFields(rest))
+
+	return b.String()
+}
+
+// levelLabel it's the level of record as printed by DefaultHandler
+func levelLabel(record Record) string {
+	switch record.Level {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelFatal:
+		return "FATAL"
+	}
+
+	return fmt.Sprint(record.Level)
+}
@@ -1,61 +1,305 @@
 package logger
 
 import (
+	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type (
 	DefaultHandler struct {
+		// written is first to keep it 64-bit aligned for atomic operations
+		written uint64
+
 		DebugLogger *log.Logger
 		InfoLogger  *log.Logger
 		WarnLogger  *log.Logger
 		ErrorLogger *log.Logger
 		FatalLogger *log.Logger
+
+		// Output when set receives every level without an output of its own (see SetOutput), otherwise error and
+		// fatal go to stderr and the rest to stdout
+		Output io.Writer
+		// IndentMultiline prefixes the continuation lines of a multi-line message with multilineMarker
+		IndentMultiline bool
+		// EscapeNewlines replaces the newlines of a message with a literal \n, keeping one record per line
+		EscapeNewlines bool
+		// RelativeTime prefixes the messages with the time elapsed since the handler was first initialized, like
+		// "+1.250s", handy for short-lived programs
+		RelativeTime bool
+		// Formatter when set renders the lines instead of the log.Loggers, e.g. a TextFormatter with the time, PID or
+		// colors. The messages are still escaped, indented and prefixed with the relative time as configured above
+		Formatter Formatter
+
+		// lock makes every line a single Write to the outputs, no matter the level, and protects outputs
+		lock    sync.Mutex
+		outputs map[Level]io.Writer
+		// state protects namespace, start and the log.Loggers, that Init replaces while other goroutines log
+		state     sync.RWMutex
+		start     time.Time
+		namespace string
 	}
 )
 
+const multilineMarker = "    | "
+
 func (handler *DefaultHandler) Init(namespace string, level Level) {
-	if namespace != "" {
-		namespace = "<" + namespace + "> "
+	// the level is not used to discard output, the Logger already gates the messages and a context may raise the
+	// level of a single call
+	prefix := namespacePrefix(namespace)
+	debugLogger := log.New(handler.writer(LevelDebug), prefix+"[DEBUG] ", 0)
+	infoLogger := log.New(handler.writer(LevelInfo), prefix+"[INFO] ", 0)
+	warnLogger := log.New(handler.writer(LevelWarn), prefix+"[WARN] ", 0)
+	errorLogger := log.New(handler.writer(LevelError), prefix+"[ERROR] ", 0)
+	fatalLogger := log.New(handler.writer(LevelError), prefix+"[FATAL] ", 0)
+
+	handler.state.Lock()
+	defer handler.state.Unlock()
+
+	handler.namespace = namespace
+	if handler.start.IsZero() {
+		handler.start = now()
+	}
+	handler.DebugLogger, handler.InfoLogger, handler.WarnLogger = debugLogger, infoLogger, warnLogger
+	handler.ErrorLogger, handler.FatalLogger = errorLogger, fatalLogger
+}
+
+// levelLogger return the log.Logger of level, LevelFatal for the fatal one, and the namespace of the handler
+func (handler *DefaultHandler) levelLogger(level Level) (*log.Logger, string) {
+	handler.state.RLock()
+	defer handler.state.RUnlock()
+
+	switch level {
+	case LevelDebug:
+		return handler.DebugLogger, handler.namespace
+	case LevelInfo:
+		return handler.InfoLogger, handler.namespace
+	case LevelWarn:
+		return handler.WarnLogger, handler.namespace
+	case LevelError:
+		return handler.ErrorLogger, handler.namespace
+	case LevelFatal:
+		return handler.FatalLogger, handler.namespace
+	}
+
+	return nil, handler.namespace
+}
+
+// NewDefaultHandler return a handler writing debug, info and warn to out, and error and fatal to errOut
+func NewDefaultHandler(out, errOut io.Writer) *DefaultHandler {
+	handler := &DefaultHandler{}
+	handler.SetOutput(LevelDebug, out)
+	handler.SetOutput(LevelInfo, out)
+	handler.SetOutput(LevelWarn, out)
+	handler.SetOutput(LevelError, errOut)
+
+	return handler
+}
+
+// SetOutput redirect a level to w, LevelError also redirects fatal. Lines are written whole even when several
+// levels share the same writer
+func (handler *DefaultHandler) SetOutput(level Level, w io.Writer) {
+	handler.lock.Lock()
+	if handler.outputs == nil {
+		handler.outputs = map[Level]io.Writer{}
+	}
+	handler.outputs[level] = w
+	handler.lock.Unlock()
+
+	logger, _ := handler.levelLogger(level)
+	// not initialized yet, Init will pick it up
+	if logger == nil {
+		return
+	}
+
+	logger.SetOutput(handler.writer(level))
+	if level == LevelError {
+		fatalLogger, _ := handler.levelLogger(LevelFatal)
+		fatalLogger.SetOutput(handler.writer(level))
+	}
+}
+
+// stock tell whether the handler has no options, like the one added by Namespace
+func (handler *DefaultHandler) stock() bool {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	return handler.Output == nil && len(handler.outputs) == 0 &&
+		!handler.IndentMultiline && !handler.EscapeNewlines && !handler.RelativeTime && handler.Formatter == nil
+}
+
+// clone return a handler with the same options and outputs, for another namespace
+func (handler *DefaultHandler) clone() *DefaultHandler {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	clone := &DefaultHandler{
+		Output:          handler.Output,
+		IndentMultiline: handler.IndentMultiline,
+		EscapeNewlines:  handler.EscapeNewlines,
+		RelativeTime:    handler.RelativeTime,
+		Formatter:       handler.Formatter,
+	}
+	for level, w := range handler.outputs {
+		if clone.outputs == nil {
+			clone.outputs = map[Level]io.Writer{}
+		}
+		clone.outputs[level] = w
+	}
+
+	return clone
+}
+
+func (handler *DefaultHandler) writer(level Level) io.Writer {
+	handler.lock.Lock()
+	w := handler.outputs[level]
+	handler.lock.Unlock()
+
+	if w == nil {
+		w = handler.Output
+	}
+	if w == nil {
+		w = os.Stdout
+		if level <= LevelError {
+			w = os.Stderr
+		}
+	}
+
+	return &lockedWriter{lock: &handler.lock, count: &handler.written, writer: w}
+}
+
+func namespacePrefix(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+
+	return "<" + namespace + "> "
+}
+
+func (handler *DefaultHandler) format(msg string) string {
+	if handler.EscapeNewlines {
+		msg = strings.Replace(msg, "\n", `\n`, -1)
+	} else if handler.IndentMultiline {
+		msg = strings.Replace(msg, "\n", "\n"+multilineMarker, -1)
+	}
+
+	if handler.RelativeTime {
+		handler.state.RLock()
+		start := handler.start
+		handler.state.RUnlock()
+
+		msg = fmt.Sprintf("+%.3fs %s", now().Sub(start).Seconds(), msg)
+	}
+
+	return msg
+}
+
+// Log writes the record like the level functions, but using the namespace of the record
+func (handler *DefaultHandler) Log(record Record) {
+	var label string
+	switch record.Level {
+	case LevelDebug:
+		label = "[DEBUG] "
+	case LevelInfo:
+		label = "[INFO] "
+	case LevelWarn:
+		label = "[WARN] "
+	case LevelError:
+		label = "[ERROR] "
+	default:
+		return
+	}
+
+	logger, namespace := handler.levelLogger(record.Level)
+	if handler.Formatter != nil {
+		handler.writeFormatted(logger, record)
+		return
+	}
+
+	msg := handler.format(record.Message) + formatFields(record.Fields)
+	if record.Namespace == namespace {
+		logger.Println(msg)
+		return
 	}
 
-	var debugOutput, infoOutput, warnOutput io.Writer
-	if level == LevelDebug {
-		debugOutput, infoOutput, warnOutput = os.Stdout, os.Stdout, os.Stdout
-	} else if level == LevelInfo {
-		debugOutput = ioutil.Discard
-		infoOutput, warnOutput = os.Stdout, os.Stdout
-	} else {
-		debugOutput, infoOutput = ioutil.Discard, ioutil.Discard
-		warnOutput = os.Stdout
+	io.WriteString(logger.Writer(), namespacePrefix(record.Namespace)+label+msg+"\n")
+}
+
+func (handler *DefaultHandler) FatalFields(msg string, fields map[string]interface{}) {
+	handler.print(LevelFatal, msg, fields)
+}
+
+// print writes msg with the namespace of the handler, for the level functions that don't get a record
+func (handler *DefaultHandler) print(level Level, msg string, fields map[string]interface{}) {
+	logger, namespace := handler.levelLogger(level)
+	if handler.Formatter != nil {
+		handler.writeFormatted(logger, Record{Level: level, Namespace: namespace, Message: msg, Time: now(), Fields: fields})
+		return
+	}
+
+	logger.Println(handler.format(msg) + formatFields(fields))
+}
+
+func (handler *DefaultHandler) writeFormatted(logger *log.Logger, record Record) {
+	record.Message = handler.format(record.Message)
+	io.WriteString(logger.Writer(), handler.Formatter.Format(record)+"\n")
+}
+
+// formatFields render fields as " key=value" sorted by key, quoting the values with spaces
+func formatFields(fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		value := fmt.Sprint(fields[key])
+		if value == "" || strings.ContainsAny(value, " =\"\n") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(" " + key + "=" + value)
 	}
 
-	handler.DebugLogger = log.New(debugOutput, namespace+"[DEBUG] ", 0)
-	handler.InfoLogger = log.New(infoOutput, namespace+"[INFO] ", 0)
-	handler.WarnLogger = log.New(warnOutput, namespace+"[WARN] ", 0)
-	handler.ErrorLogger = log.New(os.Stderr, namespace+"[ERROR] ", 0)
-	handler.FatalLogger = log.New(os.Stderr, namespace+"[FATAL] ", 0)
+	return b.String()
 }
 
 func (handler *DefaultHandler) Debug(msg string) {
-	handler.DebugLogger.Println(msg)
+	handler.print(LevelDebug, msg, nil)
 }
 
 func (handler *DefaultHandler) Info(msg string) {
-	handler.InfoLogger.Println(msg)
+	handler.print(LevelInfo, msg, nil)
 }
 
 func (handler *DefaultHandler) Warn(msg string) {
-	handler.WarnLogger.Println(msg)
+	handler.print(LevelWarn, msg, nil)
 }
 
 func (handler *DefaultHandler) Error(msg string) {
-	handler.ErrorLogger.Println(msg)
+	handler.print(LevelError, msg, nil)
 }
 
 func (handler *DefaultHandler) Fatal(msg string) {
-	handler.FatalLogger.Println(msg)
+	handler.print(LevelFatal, msg, nil)
+}
+
+func (handler *DefaultHandler) BytesWritten() uint64 {
+	return atomic.LoadUint64(&handler.written)
+}
+
+func (handler *DefaultHandler) ResetBytes() {
+	atomic.StoreUint64(&handler.written, 0)
 }
@@ -0,0 +1,274 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// lineWriter fails the test when writes overlap or a write is not exactly one line
+type lineWriter struct {
+	t        *testing.T
+	inFlight int32
+	lines    int32
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	if atomic.AddInt32(&w.inFlight, 1) != 1 {
+		w.t.Error("concurrent Write on the same handler")
+	}
+	defer atomic.AddInt32(&w.inFlight, -1)
+	runtime.Gosched()
+
+	if bytes.Count(b, []byte("\n")) != 1 || b[len(b)-1] != '\n' {
+		w.t.Errorf("expected a single full line per Write, got %q", b)
+	}
+	atomic.AddInt32(&w.lines, 1)
+
+	return len(b), nil
+}
+
+func TestDefaultHandlerWritesAtomicLines(t *testing.T) {
+	const goroutines, messages = 50, 200
+
+	writer := &lineWriter{t: t}
+	handler := &DefaultHandler{Output: writer}
+	handler.Init("stress", LevelDebug)
+
+	wait := sync.WaitGroup{}
+	wait.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wait.Done()
+			msg := strings.Repeat("x", 512)
+			for j := 0; j < messages; j++ {
+				handler.Debug(msg)
+				handler.Info(msg)
+				handler.Error(msg)
+			}
+		}()
+	}
+	wait.Wait()
+
+	if writer.lines != goroutines*messages*3 {
+		t.Fatal("expected", goroutines*messages*3, "lines, got", writer.lines)
+	}
+}
+
+func TestFanoutHandlerFormatsPerHandler(t *testing.T) {
+	TestMode = true
+	defer func() { TestMode = false }()
+
+	var text, container bytes.Buffer
+	handler := NewFanoutHandler(&DefaultHandler{Output: &text}, NewContainerHandler(&container))
+	handler.Init("fanout", LevelInfo)
+
+	handler.Info("hello")
+
+	if text.String() != "<fanout> [INFO] hello\n" {
+		t.Fatalf("unexpected text output %q", text.String())
+	}
+	var entry map[string]string
+	if err := json.Unmarshal(container.Bytes(), &entry); err != nil || entry["log"] != "<fanout> [INFO] hello\n" {
+		t.Fatalf("unexpected container output %q", container.String())
+	}
+}
+
+func TestLazyHandlerBuildsOnFirstMessage(t *testing.T) {
+	var buf bytes.Buffer
+	built := 0
+	handler := LazyHandler(func() Interface {
+		built++
+		return &DefaultHandler{Output: &buf}
+	})
+
+	log := NewLogger("lazy", LevelInfo)
+	log.AddHandler(handler)
+	log.Debug("below level")
+	if built != 0 {
+		t.Fatal("expected handler not to be built before the first message")
+	}
+
+	log.Info("first")
+	log.Info("second")
+	if built != 1 {
+		t.Fatal("expected handler to be built once, got", built)
+	}
+	if buf.String() != "<lazy> [INFO] first\n<lazy> [INFO] second\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestDualHandlerWritesJSONAndText(t *testing.T) {
+	dual := NewDualHandler(LevelWarn)
+	jsonHandler := dual.handlers[0].(*JSONHandler)
+	textHandler := dual.handlers[1].(*levelHandler).handler.(*DefaultHandler)
+	if jsonHandler.Output != os.Stdout || textHandler.Output != os.Stderr {
+		t.Fatal("expected JSON on stdout and text on stderr")
+	}
+
+	var jsonOut, textOut bytes.Buffer
+	jsonHandler.Output, textHandler.Output = &jsonOut, &textOut
+	log := NewLogger("dual", LevelInfo, dual)
+	log.Info("routine")
+	log.Warn("attention")
+
+	if strings.Count(jsonOut.String(), "\n") != 2 || textOut.String() != "<dual> [WARN] attention\n" {
+		t.Fatalf("unexpected output %q %q", jsonOut.String(), textOut.String())
+	}
+}
+
+func TestDefaultHandlerRelativeTime(t *testing.T) {
+	TestMode = true
+	defer func() { TestMode = false }()
+
+	var buf bytes.Buffer
+	handler := &DefaultHandler{Output: &buf, RelativeTime: true, IndentMultiline: true}
+	handler.Init("cli", LevelInfo)
+	handler.Info("started\nsecond line")
+
+	if buf.String() != "<cli> [INFO] +0.000s started\n    | second line\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestDefaultHandlerMultilineModes(t *testing.T) {
+	record := Record{Level: LevelInfo, Namespace: "ml", Message: "first\nsecond", Fields: map[string]interface{}{"note": "x\ny"}}
+	for _, test := range []struct {
+		handler  *DefaultHandler
+		expected string
+	}{
+		{&DefaultHandler{}, "<ml> [INFO] first\nsecond note=\"x\\ny\"\n"},
+		{&DefaultHandler{IndentMultiline: true}, "<ml> [INFO] first\n    | second note=\"x\\ny\"\n"},
+		{&DefaultHandler{EscapeNewlines: true}, "<ml> [INFO] first\\nsecond note=\"x\\ny\"\n"},
+		{&DefaultHandler{EscapeNewlines: true, IndentMultiline: true}, "<ml> [INFO] first\\nsecond note=\"x\\ny\"\n"},
+	} {
+		var buf bytes.Buffer
+		test.handler.Output = &buf
+		test.handler.Init("ml", LevelInfo)
+		test.handler.Log(record)
+
+		if buf.String() != test.expected {
+			t.Fatalf("expected %q, got %q", test.expected, buf.String())
+		}
+	}
+}
+
+func TestFirstThenSampleHandlerKeepsNovelMessages(t *testing.T) {
+	var buf bytes.Buffer
+	sampler := NewFirstThenSampleHandler(&DefaultHandler{Output: &buf}, 3, 2)
+	sampler.Init("sample", LevelDebug)
+
+	for i := 0; i < 7; i++ {
+		sampler.Error("timeout")
+	}
+	sampler.Error("refused")
+
+	expected := strings.Repeat("<sample> [ERROR] timeout\n", 3) + "<sample> [ERROR] refused\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+	if metrics := sampler.Metrics(); metrics.Emitted != 4 || metrics.Dropped != 4 {
+		t.Fatal("unexpected metrics", metrics)
+	}
+}
+
+func TestDefaultHandlerRoutesLevels(t *testing.T) {
+	var out, errOut, debugOut bytes.Buffer
+	handler := NewDefaultHandler(&out, &errOut)
+	handler.Init("routes", LevelDebug)
+	handler.SetOutput(LevelDebug, &debugOut)
+
+	handler.Debug("debug")
+	handler.Info("info")
+	handler.Error("error")
+	handler.Fatal("fatal")
+
+	if debugOut.String() != "<routes> [DEBUG] debug\n" || out.String() != "<routes> [INFO] info\n" {
+		t.Fatalf("unexpected stdout routing %q %q", debugOut.String(), out.String())
+	}
+	if errOut.String() != "<routes> [ERROR] error\n<routes> [FATAL] fatal\n" {
+		t.Fatalf("unexpected stderr routing %q", errOut.String())
+	}
+	if handler.BytesWritten() != uint64(debugOut.Len()+out.Len()+errOut.Len()) {
+		t.Fatal("expected every level to be counted, got", handler.BytesWritten())
+	}
+}
+
+type gatedHandler struct {
+	gate   chan struct{}
+	memory *MemoryHandler
+}
+
+func (handler *gatedHandler) Log(record Record) {
+	<-handler.gate
+	handler.memory.Log(record)
+}
+
+func TestAsyncHandlerFlushKeepsOrder(t *testing.T) {
+	memory := NewMemoryHandler()
+	async := NewAsyncHandler(memory, 4)
+	log := NewLogger("async", LevelInfo, async)
+
+	for i := 0; i < 100; i++ {
+		log.Info("message %d", i)
+	}
+	async.Flush()
+
+	entries := memory.Entries()
+	if len(entries) != 100 || entries[0].Msg != "message 0" || entries[99].Msg != "message 99" {
+		t.Fatal("expected every message in order, got", len(entries))
+	}
+	if err := async.Close(); err != nil || async.Dropped() != 0 {
+		t.Fatal("unexpected close", err, async.Dropped())
+	}
+}
+
+func TestAsyncHandlerDropsWhenFull(t *testing.T) {
+	inner := &gatedHandler{gate: make(chan struct{}), memory: NewMemoryHandler()}
+	async := NewAsyncHandler(inner, 2)
+	async.DropWhenFull = true
+	log := NewLogger("", LevelInfo, async)
+
+	for i := 0; i < 10; i++ {
+		log.Info("message %d", i)
+	}
+	close(inner.gate)
+	async.Close()
+	log.Info("after close")
+
+	kept := uint64(len(inner.memory.Entries()))
+	if async.Dropped() < 7 || kept+async.Dropped() != 11 {
+		t.Fatal("expected the messages over the buffer to be dropped, got", kept, async.Dropped())
+	}
+}
+
+func TestDefaultHandlerFormatter(t *testing.T) {
+	TestMode = true
+	defer func() { TestMode = false }()
+
+	var buf bytes.Buffer
+	handler := &DefaultHandler{Output: &buf, Formatter: &TextFormatter{
+		TimeLayout: "15:04:05",
+		FieldOrder: []string{"user"},
+		Color:      true,
+	}}
+	handler.Init("format", LevelDebug)
+	handler.Log(Record{Level: LevelWarn, Namespace: "format", Message: "slow", Time: now(),
+		Fields: map[string]interface{}{"user": "ana", "elapsed": "2s"}})
+	handler.Fatal("bye")
+
+	expected := "00:00:00 <format> \x1b[33m[WARN]\x1b[0m slow user=ana elapsed=2s\n" +
+		"00:00:00 <format> \x1b[35m[FATAL]\x1b[0m bye\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+	if IsTerminal(&buf) {
+		t.Fatal("a buffer is not a terminal")
+	}
+}
@@ -0,0 +1,98 @@
+package logger
+
+import "io"
+
+// levelHandler forwards to handler only the messages at level or more severe
+type levelHandler struct {
+	handler Interface
+	level   Level
+}
+
+// AddHandlerWithLevel add a handler that only gets the messages at level or more severe, e.g. debug to a file and only
+// errors to the console. The level of the logger still applies first, so it must be at least as verbose as the most
+// verbose handler
+func (logger *Logger) AddHandlerWithLevel(handler Interface, level Level) {
+	logger.AddHandler(&levelHandler{handler: handler, level: level})
+}
+
+// AddHandlerWithLevel ...
+func AddHandlerWithLevel(handler Interface, level Level) {
+	DefaultLogger.AddHandlerWithLevel(handler, level)
+}
+
+// Init forwards the least verbose of both levels
+func (handler *levelHandler) Init(namespace string, level Level) {
+	if level > handler.level {
+		level = handler.level
+	}
+	if initHandler, ok := handler.handler.(InitInterface); ok {
+		initHandler.Init(namespace, level)
+	}
+}
+
+func (handler *levelHandler) Log(record Record) {
+	if record.Level <= handler.level {
+		dispatchTo(handler.handler, record)
+	}
+}
+
+func (handler *levelHandler) Fatal(msg string) {
+	if fatalHandler, ok := handler.handler.(FatalInterface); ok && handler.level >= LevelFatal {
+		fatalHandler.Fatal(msg)
+	}
+}
+
+func (handler *levelHandler) FatalFields(msg string, fields map[string]interface{}) {
+	if handler.level < LevelFatal {
+		return
+	}
+
+	if fatalHandler, ok := handler.handler.(FatalFieldsInterface); ok {
+		fatalHandler.FatalFields(msg, fields)
+	} else if fatalHandler, ok := handler.handler.(FatalInterface); ok {
+		fatalHandler.Fatal(msg)
+	}
+}
+
+func (handler *levelHandler) Metrics() HandlerMetrics {
+	if metricsHandler, ok := handler.handler.(MetricsInterface); ok {
+		return metricsHandler.Metrics()
+	}
+
+	return HandlerMetrics{}
+}
+
+func (handler *levelHandler) BytesWritten() uint64 {
+	if counterHandler, ok := handler.handler.(BytesCounterInterface); ok {
+		return counterHandler.BytesWritten()
+	}
+
+	return 0
+}
+
+func (handler *levelHandler) ResetBytes() {
+	if counterHandler, ok := handler.handler.(BytesCounterInterface); ok {
+		counterHandler.ResetBytes()
+	}
+}
+
+func (handler *levelHandler) Close() error {
+	if closer, ok := handler.handler.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// unwrapHandler return the handler added by AddHandlerWithLevel or inherited from an ancestor, so it can be found by
+// RemoveHandler
+func unwrapHandler(handler Interface) Interface {
+	if levelHandler, ok := handler.(*levelHandler); ok {
+		return levelHandler.handler
+	}
+	if inheritedHandler, ok := handler.(*inheritedHandler); ok {
+		return inheritedHandler.handler
+	}
+
+	return handler
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// startTime it's used to report the uptime on heartbeats
+var startTime = time.Now()
+
+const defaultHeartbeatInterval = time.Minute
+
+// Heartbeat log a "still alive" message with the uptime and the count of messages per level every interval, call
+// the returned function to stop it. An interval of 0 or less means the default of a minute, and level is clamped
+// between LevelError and LevelDebug
+func (logger *Logger) Heartbeat(interval time.Duration, level Level) (stop func()) {
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	if level < LevelError {
+		level = LevelError
+	} else if level > LevelDebug {
+		level = LevelDebug
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				logger.heartbeat(level)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+func (logger *Logger) heartbeat(level Level) {
+	format := "heartbeat uptime=%s debug=%d info=%d warn=%d error=%d"
+	v := []interface{}{
+		time.Since(startTime).Round(time.Second),
+		logger.counters.emitted(LevelDebug),
+		logger.counters.emitted(LevelInfo),
+		logger.counters.emitted(LevelWarn),
+		logger.counters.emitted(LevelError),
+	}
+
+	logger.log(logger.GetLevel(), level, format, v...)
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+)
+
+// parentNamespace return "app.db" for "app.db.pool", and "" for namespaces without dots
+func parentNamespace(namespace string) string {
+	if i := strings.LastIndex(namespace, "."); i >= 0 {
+		return namespace[:i]
+	}
+
+	return ""
+}
+
+// ancestorHandlers return the handlers of the nearest registered ancestor of namespace that has more than the stock
+// DefaultHandler, with its DefaultHandlers copied so they print the new namespace and the others wrapped so they aren't
+// initialized again with the new namespace. It must be called with loggersLock held
+func ancestorHandlers(namespace string) []Interface {
+	for ns := parentNamespace(namespace); ns != ""; ns = parentNamespace(ns) {
+		ancestor, ok := loggers[strings.ToLower(ns)]
+		if !ok {
+			continue
+		}
+
+		handlers := ancestor.Handlers()
+		if len(handlers) == 1 {
+			if defaultHandler, ok := handlers[0].(*DefaultHandler); ok && defaultHandler.stock() {
+				continue
+			}
+		}
+
+		for i, handler := range handlers {
+			if defaultHandler, ok := handler.(*DefaultHandler); ok {
+				handlers[i] = defaultHandler.clone()
+			} else {
+				handlers[i] = &inheritedHandler{handler: handler}
+			}
+		}
+		return handlers
+	}
+
+	return nil
+}
+
+// inheritedHandler shares a handler of an ancestor without initializing nor closing it, RecordInterface handlers still
+// see the namespace of the descendant in its records
+type inheritedHandler struct {
+	handler Interface
+}
+
+func (handler *inheritedHandler) Log(record Record) {
+	dispatchTo(handler.handler, record)
+}
+
+func (handler *inheritedHandler) Fatal(msg string) {
+	if fatalHandler, ok := handler.handler.(FatalInterface); ok {
+		fatalHandler.Fatal(msg)
+	}
+}
+
+func (handler *inheritedHandler) FatalFields(msg string, fields map[string]interface{}) {
+	if fatalHandler, ok := handler.handler.(FatalFieldsInterface); ok {
+		fatalHandler.FatalFields(msg, fields)
+	} else if fatalHandler, ok := handler.handler.(FatalInterface); ok {
+		fatalHandler.Fatal(msg)
+	}
+}
+
+func (handler *inheritedHandler) Flush() {
+	if flushHandler, ok := handler.handler.(FlushInterface); ok {
+		flushHandler.Flush()
+	}
+}
+
+func (handler *inheritedHandler) Metrics() HandlerMetrics {
+	if metricsHandler, ok := handler.handler.(MetricsInterface); ok {
+		return metricsHandler.Metrics()
+	}
+
+	return HandlerMetrics{}
+}
+
+// cascadeLevel apply the level of logger to the registered descendants that don't have a level of their own, from an
+// environment variable or SetLevel, nor a nearer ancestor with one. It must be called with loggersLock held, the
+// returned changes must be notified after releasing it
+func (logger *Logger) cascadeLevel() []levelChange {
+	if loggers[strings.ToLower(logger.Namespace)] != logger {
+		return nil
+	}
+
+	var changes []levelChange
+	for _, descendant := range sortedLoggers() {
+		prefix := strings.ToLower(logger.Namespace) + "."
+		if descendant.explicitLevel || !strings.HasPrefix(strings.ToLower(descendant.Namespace), prefix) {
+			continue
+		}
+		changes = append(changes, descendant.swapLevel(resolveLevel(descendant.Namespace)))
+	}
+
+	return changes
+}
+
+// sortedLoggers return the registered loggers sorted by namespace, so ancestors come before their descendants. It must
+// be called with loggersLock held
+func sortedLoggers() []*Logger {
+	sorted := make([]*Logger, 0, len(loggers))
+	for _, logger := range loggers {
+		sorted = append(sorted, logger)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Namespace) < strings.ToLower(sorted[j].Namespace)
+	})
+
+	return sorted
+}
@@ -0,0 +1,68 @@
+package logger
+
+import "sync"
+
+// Hook can change a record in place, return another one or return nil to drop it. The fields of the record are a
+// copy, hooks are free to change them
+type Hook func(record *Record) *Record
+
+var globalHooks []Hook
+var globalHooksLock sync.Mutex
+
+// AddHook add a hook run by every logger, before the hooks of the logger itself, e.g. to add the hostname to every
+// message or to redact secrets
+func AddHook(hook func(record *Record) *Record) {
+	globalHooksLock.Lock()
+	defer globalHooksLock.Unlock()
+
+	hooks := make([]Hook, len(globalHooks), len(globalHooks)+1)
+	copy(hooks, globalHooks)
+	globalHooks = append(hooks, hook)
+}
+
+// AddHook add a hook run on the messages of logger after the level check and before the filters, in the order they
+// were added. Fatal messages go through the hooks too, but they can't be dropped
+func (logger *Logger) AddHook(hook func(record *Record) *Record) {
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	hooks := make([]Hook, len(logger.hooks), len(logger.hooks)+1)
+	copy(hooks, logger.hooks)
+	logger.hooks = append(hooks, hook)
+}
+
+func (logger *Logger) currentHooks() []Hook {
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	return logger.hooks
+}
+
+// runHooks return the record changed by the global hooks and the ones of logger and then redacted, false when a hook
+// dropped it
+func (logger *Logger) runHooks(record Record) (Record, bool) {
+	globalHooksLock.Lock()
+	hooks := globalHooks
+	globalHooksLock.Unlock()
+	own := logger.currentHooks()
+
+	if len(hooks) == 0 && len(own) == 0 && !redacting() {
+		return record, true
+	}
+
+	fields := make(map[string]interface{}, len(record.Fields))
+	for key, value := range record.Fields {
+		fields[key] = value
+	}
+	record.Fields = fields
+
+	current := &record
+	for _, hook := range append(hooks[:len(hooks):len(hooks)], own...) {
+		if current = hook(current); current == nil {
+			return record, false
+		}
+	}
+	redact(current)
+
+	return *current, true
+}
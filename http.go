@@ -17,6 +17,8 @@ func levelToString(level Level) string {
 		return "warn"
 	} else if level == LevelError {
 		return "error"
+	} else if level == LevelFatal {
+		return "fatal"
 	} else {
 		return ""
 	}
@@ -27,23 +29,32 @@ func HTTPHandler() http.Handler {
 	return http.HandlerFunc(HTTPFunc)
 }
 
+// AdminHandler it's HTTPHandler, meant to be mounted on an internal admin port. GET lists the namespaces and their
+// levels (or a single one at /<namespace>), PUT or POST {"namespace": "db", "level": "debug"} changes a level, the
+// namespace "all" changes every one of them
+func AdminHandler() http.Handler {
+	return HTTPHandler()
+}
+
 // HTTPFunc permit you control level of all your namespace, and change it in execution time
 func HTTPFunc(w http.ResponseWriter, r *http.Request) {
 	lastpart := strings.LastIndex(r.RequestURI, "/")
-	namespace := r.RequestURI[lastpart+1:]
+	namespace := strings.ToLower(r.RequestURI[lastpart+1:])
 
 	// Get list of namespaces and levels
 	if r.Method == "GET" {
 		// Get all namespaces
 		if lastpart == 0 {
-			namespaces := make(map[string]string, 0)
-			for namespace, logger := range loggers {
-				namespace = logger.Namespace
+			loggersLock.Lock()
+			namespaces := make(map[string]string, len(loggers))
+			for _, logger := range loggers {
+				namespace := logger.Namespace
 				if namespace == "" {
 					namespace = "_default_"
 				}
-				namespaces[namespace] = levelToString(logger.Level)
+				namespaces[namespace] = levelToString(logger.GetLevel())
 			}
+			loggersLock.Unlock()
 
 			json, _ := json.Marshal(&namespaces)
 
@@ -53,10 +64,14 @@ func HTTPFunc(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if logger, ok := loggers[namespace]; ok {
+		loggersLock.Lock()
+		logger, ok := loggers[namespace]
+		loggersLock.Unlock()
+
+		if ok {
 			loggerObj := make(map[string]string, 0)
 			loggerObj["namespace"] = logger.Namespace
-			loggerObj["level"] = levelToString(logger.Level)
+			loggerObj["level"] = levelToString(logger.GetLevel())
 
 			json, _ := json.Marshal(&loggerObj)
 
@@ -69,38 +84,36 @@ func HTTPFunc(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if r.Method == "PUT" {
+	if r.Method == "PUT" || r.Method == "POST" {
 		var userLevel map[string]interface{}
 		decoder := json.NewDecoder(r.Body)
 		if err := decoder.Decode(&userLevel); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		if userLevel["level"] == nil {
+		levelName, ok := userLevel["level"].(string)
+		if !ok {
 			http.Error(w, "missing 'level' field", http.StatusBadRequest)
 			return
 		}
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
 		if lastpart == 0 {
-			if userLevel["namespace"] == nil {
+			name, ok := userLevel["namespace"].(string)
+			if !ok {
 				http.Error(w, "missing 'namespace' field", http.StatusBadRequest)
 				return
 			}
 
-			namespace = userLevel["namespace"].(string)
+			namespace = strings.ToLower(name)
 		}
 
-		level := GetLevelByString(userLevel["level"].(string))
-		if namespace == "all" {
-			for _, logger := range loggers {
-				logger.SetLevel(level)
-			}
-		} else if logger, ok := loggers[namespace]; ok {
-			logger.SetLevel(level)
-		} else if namespace == "" {
-			DefaultLogger.SetLevel(level)
-		} else {
+		if !setLevelByName(namespace, level) {
 			http.Error(w, fmt.Sprintf("namespace '%s' not found", namespace), http.StatusNotFound)
 			return
 		}
@@ -113,3 +126,26 @@ func HTTPFunc(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 	io.WriteString(w, http.StatusText(http.StatusNotImplemented))
 }
+
+// setLevelByName set the level of the lowercase namespace, "all" sets every namespace, and tell whether it was found
+func setLevelByName(namespace string, level Level) bool {
+	loggersLock.Lock()
+	var changes []levelChange
+	if namespace == "all" {
+		for _, logger := range loggers {
+			changes = append(changes, logger.setExplicitLevel(level)...)
+		}
+	} else if logger, ok := loggers[namespace]; ok {
+		changes = logger.setExplicitLevel(level)
+	} else if namespace == "" {
+		changes = DefaultLogger.setExplicitLevel(level)
+	} else {
+		loggersLock.Unlock()
+		return false
+	}
+	loggersLock.Unlock()
+
+	notifyLevels(changes)
+
+	return true
+}
@@ -1,5 +1,3 @@
-//+build ignore
-
 package logger
 
 import (
@@ -21,7 +19,7 @@ func TestShouldChangeAllNamespacesLevel(t *testing.T) {
 
 	HTTPFunc(w, req)
 
-	if firstNamespace.Level != 3 || secondNamespace.Level != 3 {
-		t.Fatal("Level should be", jsonStr, "But got", firstNamespace.Level, secondNamespace.Level)
+	if firstNamespace.GetLevel() != LevelDebug || secondNamespace.GetLevel() != LevelDebug {
+		t.Fatal("Level should be", jsonStr, "But got", firstNamespace.GetLevel(), secondNamespace.GetLevel())
 	}
 }
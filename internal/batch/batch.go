@@ -0,0 +1,189 @@
+// Package batch ships items in batches from a background goroutine, it's shared by the handlers that send records to a
+// remote endpoint
+package batch
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Batcher collects the items of a bounded queue and sends them in batches of up to batchSize, or every interval. A
+// failed batch is kept and retried with exponential backoff, up to maxBackoff, while new items wait in the queue, when
+// the queue is full new items are dropped and counted
+type Batcher struct {
+	// the counters are first to keep them 64-bit aligned for atomic operations
+	dropped uint64
+	failed  uint64
+	sent    uint64
+
+	send       func(items []interface{}) error
+	batchSize  int
+	interval   time.Duration
+	maxBackoff time.Duration
+
+	queue     chan interface{}
+	flushes   chan chan struct{}
+	done      chan struct{}
+	finished  chan struct{}
+	closeOnce sync.Once
+}
+
+// New start a batcher calling send from its own goroutine, send must return an error when the batch wasn't delivered
+func New(send func(items []interface{}) error, queueSize, batchSize int, interval, maxBackoff time.Duration) *Batcher {
+	batcher := &Batcher{
+		send:       send,
+		batchSize:  batchSize,
+		interval:   interval,
+		maxBackoff: maxBackoff,
+		queue:      make(chan interface{}, queueSize),
+		flushes:    make(chan chan struct{}),
+		done:       make(chan struct{}),
+		finished:   make(chan struct{}),
+	}
+
+	go batcher.run()
+
+	return batcher
+}
+
+// Closed tell whether Close was called, so the caller can skip preparing an item that would be dropped
+func (batcher *Batcher) Closed() bool {
+	select {
+	case <-batcher.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Add queues item without blocking, it's dropped when the queue is full or the batcher is closed
+func (batcher *Batcher) Add(item interface{}) {
+	if batcher.Closed() {
+		batcher.Drop()
+		return
+	}
+
+	select {
+	case batcher.queue <- item:
+	default:
+		batcher.Drop()
+	}
+}
+
+// Drop count an item that couldn't be queued, e.g. because it couldn't be encoded
+func (batcher *Batcher) Drop() {
+	atomic.AddUint64(&batcher.dropped, 1)
+}
+
+// Dropped ...
+func (batcher *Batcher) Dropped() uint64 {
+	return atomic.LoadUint64(&batcher.dropped)
+}
+
+// Failed it's the number of failed attempts to send a batch
+func (batcher *Batcher) Failed() uint64 {
+	return atomic.LoadUint64(&batcher.failed)
+}
+
+// Sent it's the number of items delivered
+func (batcher *Batcher) Sent() uint64 {
+	return atomic.LoadUint64(&batcher.sent)
+}
+
+// Flush make an attempt to send the queued items right away, ignoring the backoff, and return when it's done. A failed
+// batch is kept and retried like any other
+func (batcher *Batcher) Flush() {
+	flushed := make(chan struct{})
+	select {
+	case batcher.flushes <- flushed:
+		<-flushed
+	case <-batcher.finished:
+	}
+}
+
+// Close stop accepting items and make a last attempt to send the queued ones, it returns when the goroutine is done
+func (batcher *Batcher) Close() {
+	batcher.closeOnce.Do(func() {
+		close(batcher.done)
+	})
+	<-batcher.finished
+}
+
+func (batcher *Batcher) run() {
+	defer close(batcher.finished)
+
+	ticker := time.NewTicker(batcher.interval)
+	defer ticker.Stop()
+
+	batch := make([]interface{}, 0, batcher.batchSize)
+	backoff := batcher.interval
+	var retryAt time.Time
+
+	flush := func() {
+		if len(batch) == 0 || time.Now().Before(retryAt) {
+			return
+		}
+
+		if err := batcher.send(batch); err != nil {
+			// keep the batch and let the queue absorb new items until the endpoint is back
+			atomic.AddUint64(&batcher.failed, 1)
+			retryAt = time.Now().Add(backoff)
+			if backoff *= 2; backoff > batcher.maxBackoff {
+				backoff = batcher.maxBackoff
+			}
+			return
+		}
+
+		atomic.AddUint64(&batcher.sent, uint64(len(batch)))
+		batch = batch[:0]
+		backoff = batcher.interval
+		retryAt = time.Time{}
+	}
+
+	for {
+		// stop reading the queue while a full batch is waiting for the endpoint
+		queue := batcher.queue
+		if len(batch) >= batcher.batchSize {
+			queue = nil
+		}
+
+		select {
+		case item := <-queue:
+			batch = append(batch, item)
+			if len(batch) >= batcher.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case flushed := <-batcher.flushes:
+			for len(batcher.queue) > 0 {
+				batch = append(batch, <-batcher.queue)
+			}
+			retryAt = time.Time{}
+			flush()
+			close(flushed)
+		case <-batcher.done:
+			batcher.drain(batch)
+			return
+		}
+	}
+}
+
+// drain make a single attempt to send what is left, counting it as dropped on failure
+func (batcher *Batcher) drain(batch []interface{}) {
+	for len(batcher.queue) > 0 {
+		batch = append(batch, <-batcher.queue)
+	}
+
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := batcher.send(batch); err != nil {
+		atomic.AddUint64(&batcher.failed, 1)
+		atomic.AddUint64(&batcher.dropped, uint64(len(batch)))
+		return
+	}
+	atomic.AddUint64(&batcher.sent, uint64(len(batch)))
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JSONHandler writes one JSON object per line with the time (RFC3339Nano), level, namespace and msg of each record,
+// the fields of the record are inlined as top-level keys
+type JSONHandler struct {
+	// Output when set receives every level, otherwise error and fatal go to stderr and the rest to stdout
+	Output io.Writer
+	// Indent when set pretty-prints each record indented by it, e.g. two spaces for local development. Records still end
+	// with a single newline
+	Indent string
+
+	lock      sync.Mutex
+	namespace string
+}
+
+// NewJSONHandler ...
+func NewJSONHandler(w io.Writer) *JSONHandler {
+	return &JSONHandler{Output: w}
+}
+
+func (handler *JSONHandler) Init(namespace string, level Level) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	handler.namespace = namespace
+}
+
+func (handler *JSONHandler) Log(record Record) {
+	handler.write(levelToString(record.Level), record)
+}
+
+func (handler *JSONHandler) Debug(msg string) {
+	handler.write("debug", handler.record(LevelDebug, msg, nil))
+}
+
+func (handler *JSONHandler) Info(msg string) {
+	handler.write("info", handler.record(LevelInfo, msg, nil))
+}
+
+func (handler *JSONHandler) Warn(msg string) {
+	handler.write("warn", handler.record(LevelWarn, msg, nil))
+}
+
+func (handler *JSONHandler) Error(msg string) {
+	handler.write("error", handler.record(LevelError, msg, nil))
+}
+
+func (handler *JSONHandler) Fatal(msg string) {
+	handler.write("fatal", handler.record(LevelFatal, msg, nil))
+}
+
+func (handler *JSONHandler) FatalFields(msg string, fields map[string]interface{}) {
+	handler.write("fatal", handler.record(LevelFatal, msg, fields))
+}
+
+func (handler *JSONHandler) record(level Level, msg string, fields map[string]interface{}) Record {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	return Record{Level: level, Namespace: handler.namespace, Message: msg, Time: now(), Fields: fields}
+}
+
+func (handler *JSONHandler) write(level string, record Record) {
+	b, err := encodeIndentedJSON(level, record, handler.Indent)
+	if err != nil {
+		return
+	}
+
+	output := handler.Output
+	if output == nil {
+		output = os.Stdout
+		if record.Level <= LevelError {
+			output = os.Stderr
+		}
+	}
+
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	output.Write(append(b, '\n'))
+}
+
+// encodeJSON encode the record as a JSON object, without the trailing newline
+func encodeJSON(level string, record Record) ([]byte, error) {
+	return encodeIndentedJSON(level, record, "")
+}
+
+// encodeIndentedJSON same as encodeJSON, pretty-printed with indent when it isn't empty
+func encodeIndentedJSON(level string, record Record, indent string) ([]byte, error) {
+	marshal := json.Marshal
+	if indent != "" {
+		marshal = func(v interface{}) ([]byte, error) {
+			return json.MarshalIndent(v, "", indent)
+		}
+	}
+
+	b, err := marshal(jsonEntry(level, record, false))
+	if err != nil {
+		// some field can't be encoded, keep the record with every field as text
+		b, err = marshal(jsonEntry(level, record, true))
+	}
+
+	return b, err
+}
+
+func jsonEntry(level string, record Record, fieldsAsText bool) map[string]interface{} {
+	entry := make(map[string]interface{}, len(record.Fields)+4)
+	for key, value := range record.Fields {
+		if fieldsAsText {
+			value = fmt.Sprint(value)
+		}
+		entry[key] = value
+	}
+
+	entry["time"] = record.Time.Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["namespace"] = record.Namespace
+	entry["msg"] = record.Message
+
+	return entry
+}
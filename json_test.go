@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestJSONHandlerInlinesFields(t *testing.T) {
+	TestMode = true
+	defer func() { TestMode = false }()
+
+	var buf bytes.Buffer
+	log := NewLogger("api", LevelInfo)
+	log.AddHandler(NewJSONHandler(&buf))
+
+	log.With("status", 200).With("msg", "shadowed").Info("request done")
+
+	expected := `{"level":"info","msg":"request done","namespace":"api","status":200,"time":"2000-01-01T00:00:00Z"}` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestJSONHandlerIndent(t *testing.T) {
+	TestMode = true
+	defer func() { TestMode = false }()
+
+	var buf bytes.Buffer
+	handler := NewJSONHandler(&buf)
+	handler.Indent = "  "
+	log := NewLogger("api", LevelInfo, handler)
+
+	log.With("status", 200).Info("first")
+	log.Info("second")
+
+	record := "{\n  \"level\": \"info\",\n  \"msg\": \"first\",\n  \"namespace\": \"api\",\n  \"status\": 200,\n" +
+		"  \"time\": \"2000-01-01T00:00:00Z\"\n}\n"
+	if !strings.HasPrefix(buf.String(), record) || !strings.HasSuffix(buf.String(), "}\n") || strings.Contains(buf.String(), "\n\n") {
+		t.Fatalf("expected records ending with a single newline, got %q", buf.String())
+	}
+}
+
+func TestJSONHandlerDoesNotInterleaveLines(t *testing.T) {
+	var buf safeBuffer
+	handler := NewJSONHandler(&buf)
+	handler.Init("concurrent", LevelDebug)
+
+	wait := sync.WaitGroup{}
+	wait.Add(20)
+	for i := 0; i < 20; i++ {
+		go func() {
+			defer wait.Done()
+			for j := 0; j < 100; j++ {
+				handler.Info(strings.Repeat("y", 256))
+			}
+		}()
+	}
+	wait.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2000 {
+		t.Fatal("expected 2000 lines, got", len(lines))
+	}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+type safeBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
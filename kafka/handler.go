@@ -0,0 +1,183 @@
+// Package kafka publishes log records to a Kafka topic. It doesn't depend on any Kafka client, wrap the producer of
+// your client (sarama, kafka-go, confluent-kafka-go...) with the Producer interface.
+package kafka
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/NeowayLabs/logger"
+	"github.com/NeowayLabs/logger/internal/batch"
+)
+
+const (
+	defaultQueueSize     = 1024
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	maxRetryBackoff      = 30 * time.Second
+)
+
+type (
+	// Message ...
+	Message struct {
+		Key   []byte
+		Value []byte
+	}
+
+	// Producer it's the only thing this package needs from a Kafka client
+	Producer interface {
+		Produce(topic string, messages []Message) error
+	}
+
+	// Handler publishes every record as JSON to Topic, in batches, from a background goroutine. While the broker is
+	// unavailable records are kept in a bounded queue, when the queue is full new records are dropped and counted
+	Handler struct {
+		// KeyByNamespace use the namespace as message key, keeping each namespace in a single partition
+		KeyByNamespace bool
+
+		topic    string
+		producer Producer
+
+		namespace atomic.Value
+		batcher   *batch.Batcher
+	}
+)
+
+// NewHandler start a handler publishing to topic, queueSize bounds the records waiting to be published, 0 means the
+// default of 1024
+func NewHandler(producer Producer, topic string, queueSize int) *Handler {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	handler := &Handler{
+		topic:    topic,
+		producer: producer,
+	}
+	handler.namespace.Store("")
+	handler.batcher = batch.New(handler.publish, queueSize, defaultBatchSize, defaultFlushInterval, maxRetryBackoff)
+
+	return handler
+}
+
+func (handler *Handler) Init(namespace string, level logger.Level) {
+	handler.namespace.Store(namespace)
+}
+
+// Log publishes the record with its own namespace and time
+func (handler *Handler) Log(rec logger.Record) {
+	var level string
+	switch rec.Level {
+	case logger.LevelDebug:
+		level = "debug"
+	case logger.LevelInfo:
+		level = "info"
+	case logger.LevelWarn:
+		level = "warn"
+	default:
+		level = "error"
+	}
+
+	handler.enqueue(level, rec.Namespace, rec.Message, rec.Time, rec.Fields)
+}
+
+func (handler *Handler) Debug(msg string) {
+	handler.enqueue("debug", handler.namespace.Load().(string), msg, time.Now(), nil)
+}
+
+func (handler *Handler) Info(msg string) {
+	handler.enqueue("info", handler.namespace.Load().(string), msg, time.Now(), nil)
+}
+
+func (handler *Handler) Warn(msg string) {
+	handler.enqueue("warn", handler.namespace.Load().(string), msg, time.Now(), nil)
+}
+
+func (handler *Handler) Error(msg string) {
+	handler.enqueue("error", handler.namespace.Load().(string), msg, time.Now(), nil)
+}
+
+// Fatal publishes the message with what is queued right away, the handler stays open because Panic can be recovered
+func (handler *Handler) Fatal(msg string) {
+	handler.enqueue("fatal", handler.namespace.Load().(string), msg, time.Now(), nil)
+	handler.Flush()
+}
+
+// Flush make an attempt to publish the queued records and return when it's done
+func (handler *Handler) Flush() {
+	handler.batcher.Flush()
+}
+
+// Dropped it's the number of records lost because the queue was full or the last flush on Close failed
+func (handler *Handler) Dropped() uint64 {
+	return handler.batcher.Dropped()
+}
+
+// Failures it's the number of failed publish attempts
+func (handler *Handler) Failures() uint64 {
+	return handler.batcher.Failed()
+}
+
+// Published ...
+func (handler *Handler) Published() uint64 {
+	return handler.batcher.Sent()
+}
+
+// Metrics ...
+func (handler *Handler) Metrics() logger.HandlerMetrics {
+	return logger.HandlerMetrics{
+		Emitted: handler.Published(),
+		Dropped: handler.Dropped(),
+		Errors:  handler.Failures(),
+	}
+}
+
+// Close stop accepting records and make a last attempt to publish the queued ones
+func (handler *Handler) Close() error {
+	handler.batcher.Close()
+
+	return nil
+}
+
+// enqueue serializes the record with its fields inlined as top-level keys
+func (handler *Handler) enqueue(level, namespace, msg string, t time.Time, fields map[string]interface{}) {
+	if handler.batcher.Closed() {
+		handler.batcher.Drop()
+		return
+	}
+
+	entry := make(map[string]interface{}, len(fields)+4)
+	for key, value := range fields {
+		entry[key] = value
+	}
+	entry["time"] = t.UTC().Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["msg"] = msg
+	if namespace != "" {
+		entry["namespace"] = namespace
+	}
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		handler.batcher.Drop()
+		return
+	}
+
+	message := Message{Value: value}
+	if handler.KeyByNamespace {
+		message.Key = []byte(namespace)
+	}
+
+	handler.batcher.Add(message)
+}
+
+// publish runs in the goroutine of the batcher
+func (handler *Handler) publish(items []interface{}) error {
+	messages := make([]Message, len(items))
+	for i, item := range items {
+		messages[i] = item.(Message)
+	}
+
+	return handler.producer.Produce(handler.topic, messages)
+}
@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/NeowayLabs/logger"
+)
+
+type fakeProducer struct {
+	lock     sync.Mutex
+	down     bool
+	messages []Message
+}
+
+func (producer *fakeProducer) Produce(topic string, messages []Message) error {
+	producer.lock.Lock()
+	defer producer.lock.Unlock()
+
+	if producer.down {
+		return errors.New("broker unavailable")
+	}
+	producer.messages = append(producer.messages, messages...)
+
+	return nil
+}
+
+func TestHandlerPublishesRecordsOnClose(t *testing.T) {
+	producer := &fakeProducer{}
+	handler := NewHandler(producer, "logs", 0)
+	handler.KeyByNamespace = true
+	handler.Init("billing", logger.LevelInfo)
+
+	handler.Info("invoice created")
+	handler.Log(logger.Record{Level: logger.LevelWarn, Namespace: "tax", Message: "late", Fields: map[string]interface{}{"days": 3}})
+	handler.Close()
+
+	if len(producer.messages) != 2 {
+		t.Fatal("expected 2 messages, got", len(producer.messages))
+	}
+
+	var entry map[string]string
+	if err := json.Unmarshal(producer.messages[0].Value, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["msg"] != "invoice created" || entry["level"] != "info" || entry["namespace"] != "billing" {
+		t.Fatal("unexpected record", entry)
+	}
+	if string(producer.messages[0].Key) != "billing" {
+		t.Fatal("expected namespace key, got", string(producer.messages[0].Key))
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(producer.messages[1].Value, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["days"] != float64(3) || fields["namespace"] != "tax" {
+		t.Fatal("expected fields inlined with the record namespace, got", fields)
+	}
+}
+
+func TestHandlerDropsWhenQueueIsFull(t *testing.T) {
+	producer := &fakeProducer{down: true}
+	handler := NewHandler(producer, "logs", 2)
+
+	for i := 0; i < 10; i++ {
+		handler.Info("lost")
+	}
+	handler.Close()
+
+	if handler.Dropped() != 10 {
+		t.Fatal("expected every record to be dropped, got", handler.Dropped())
+	}
+	if handler.Failures() == 0 {
+		t.Fatal("expected publish failures to be counted")
+	}
+
+	log := logger.NamespaceWithWriter("kafka-metrics-test", &bytes.Buffer{}, logger.LevelInfo)
+	log.AddHandler(logger.NewFanoutHandler(handler))
+	if metrics := log.CollectMetrics(); metrics.Dropped != 10 || metrics.Emitted != 0 {
+		t.Fatal("unexpected metrics", metrics)
+	}
+}
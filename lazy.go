@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"io"
+	"sync"
+)
+
+// lazyHandler builds its handler on the first message, replaying the last Init
+type lazyHandler struct {
+	factory func() Interface
+	once    sync.Once
+
+	lock        sync.Mutex
+	handler     Interface
+	namespace   string
+	level       Level
+	initialized bool
+}
+
+// LazyHandler return a handler that calls factory and initializes its result only when the first message arrives, so
+// expensive handlers (e.g. remote connections) cost nothing to programs that never log at their level
+func LazyHandler(factory func() Interface) Interface {
+	return &lazyHandler{factory: factory}
+}
+
+func (lazy *lazyHandler) Init(namespace string, level Level) {
+	lazy.lock.Lock()
+	defer lazy.lock.Unlock()
+
+	lazy.namespace, lazy.level, lazy.initialized = namespace, level, true
+	if initHandler, ok := lazy.handler.(InitInterface); ok {
+		initHandler.Init(namespace, level)
+	}
+}
+
+func (lazy *lazyHandler) get() Interface {
+	lazy.once.Do(func() {
+		handler := lazy.factory()
+
+		lazy.lock.Lock()
+		defer lazy.lock.Unlock()
+
+		if initHandler, ok := handler.(InitInterface); ok && lazy.initialized {
+			initHandler.Init(lazy.namespace, lazy.level)
+		}
+		lazy.handler = handler
+	})
+
+	lazy.lock.Lock()
+	defer lazy.lock.Unlock()
+
+	return lazy.handler
+}
+
+func (lazy *lazyHandler) Log(record Record) {
+	dispatchTo(lazy.get(), record)
+}
+
+func (lazy *lazyHandler) Debug(msg string) {
+	if debugHandler, ok := lazy.get().(DebugInterface); ok {
+		debugHandler.Debug(msg)
+	}
+}
+
+func (lazy *lazyHandler) Info(msg string) {
+	if infoHandler, ok := lazy.get().(InfoInterface); ok {
+		infoHandler.Info(msg)
+	}
+}
+
+func (lazy *lazyHandler) Warn(msg string) {
+	if warnHandler, ok := lazy.get().(WarnInterface); ok {
+		warnHandler.Warn(msg)
+	}
+}
+
+func (lazy *lazyHandler) Error(msg string) {
+	if errorHandler, ok := lazy.get().(ErrorInterface); ok {
+		errorHandler.Error(msg)
+	}
+}
+
+func (lazy *lazyHandler) Fatal(msg string) {
+	if fatalHandler, ok := lazy.get().(FatalInterface); ok {
+		fatalHandler.Fatal(msg)
+	}
+}
+
+// Close close the handler if it was ever built
+func (lazy *lazyHandler) Close() error {
+	lazy.lock.Lock()
+	handler := lazy.handler
+	lazy.lock.Unlock()
+
+	if closer, ok := handler.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -3,9 +3,14 @@ package logger
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // DefaultLogger default logger
@@ -16,9 +21,24 @@ var loggersLock sync.Mutex
 // defaultEnvironmentVariablePrefix default environment variable prefix
 var defaultEnvironmentVariablePrefix = "SEVERINO_LOGGER"
 
+// TestMode makes the output of the handlers byte-stable for golden files, the clock used by handlers is fixed at
+// testModeTime
+var TestMode = false
+
+var testModeTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// namespaceLevelResolver it's protected by loggersLock
+var namespaceLevelResolver func(namespace string) (Level, bool)
+
+// StrictEnvLevels makes Namespace panic when the environment variable of a namespace holds an unknown level,
+// instead of silently falling back to info
+var StrictEnvLevels = false
+
 const (
 	// LevelNone ...
 	LevelNone Level = iota
+	// LevelFatal only lets Fatal and Panic through, it moved the levels below up by one, so store levels by name
+	LevelFatal
 	// LevelError ...
 	LevelError
 	// LevelWarn ...
@@ -59,16 +79,102 @@ type (
 	FatalInterface interface {
 		Fatal(msg string)
 	}
+	// RecordInterface handlers that implement it get the whole record of the Debug, Info, Warn and Error messages
+	// instead of the level functions, including a namespace overridden for a single call
+	RecordInterface interface {
+		Log(record Record)
+	}
+	// LevelChangeInterface this function will be called by SetLevel when the level really changes
+	LevelChangeInterface interface {
+		OnLevelChange(oldLevel, newLevel Level)
+	}
+	// MetricsInterface ...
+	MetricsInterface interface {
+		Metrics() HandlerMetrics
+	}
+	// HandlerMetrics it's the health of a handler that may drop or fail to deliver messages
+	HandlerMetrics struct {
+		Emitted uint64
+		Dropped uint64
+		Errors  uint64
+	}
+	// FlushInterface handlers that buffer messages are flushed by Fatal and Panic, after the fatal message
+	FlushInterface interface {
+		Flush()
+	}
+	// BytesCounterInterface ...
+	BytesCounterInterface interface {
+		BytesWritten() uint64
+		ResetBytes()
+	}
 
-	// Logger ...
+	// Record it's a message on its way to the handlers
+	Record struct {
+		Level     Level
+		Namespace string
+		Message   string
+		Time      time.Time
+		Fields    map[string]interface{}
+		// Caller it's the "file.go:line" of the call site when the logger reports it (see WithCaller and
+		// SetReportCaller), it's in Fields too. File, Line and Function are the full details of the same call site
+		Caller   string
+		File     string
+		Line     int
+		Function string
+	}
+
+	// Logger it's created by Namespace, or by NewLogger when it shouldn't be registered. The level and the handlers
+	// can be read and changed while other goroutines log
 	Logger struct {
 		Namespace string
-		Level     Level
-		Handlers  []Interface
+		// level it's shared with the loggers derived from this one, except the ones of AtLevel. It's accessed
+		// atomically, changes are serialized by loggersLock
+		level *uint32
+		// handlers and counters are shared with the loggers derived from this one
+		handlers *handlerList
+		counters *counters
+		// ConcurrentDispatch calls the handlers in parallel and waits for all of them, so a message costs as much as
+		// the slowest handler instead of the sum of them, useful with several network handlers
+		ConcurrentDispatch bool
+
+		// handlersLock guards the fields below that aren't accessed atomically
+		handlersLock sync.Mutex
+		// fields, filters and monitors are replaced, never changed in place
+		fields   map[string]interface{}
+		filters  []func(Record) bool
+		monitors []*rateMonitor
+		// limiter it's set from the <NAMESPACE>__RATE environment variable
+		limiter *rateLimiter
+		// sampler it's set by WithSampler or SetSampling, under handlersLock
+		sampler Sampler
+		// async it's set by SetAsync
+		async *asyncDispatch
+		// reportCaller it's 1 when set by WithCaller or SetReportCaller, accessed atomically
+		reportCaller int32
+		// hooks it's replaced by AddHook, never changed in place
+		hooks []Hook
+		// exitFunc it's set by SetExitFunc, under handlersLock
+		exitFunc func(code int)
+		// explicitLevel it's true when the level was set by SetLevel, that takes precedence over the environment
+		explicitLevel bool
 	}
 )
 
-func getEnvVarLevel(namespace string) string {
+// handlerList it's the handlers of a logger and of the loggers derived from it
+type handlerList struct {
+	lock sync.Mutex
+	// list it's replaced, never changed in place
+	list []Interface
+}
+
+// newLogger return a logger with its own level, handlers and counters
+func newLogger(namespace string, level Level) *Logger {
+	shared := uint32(level)
+
+	return &Logger{Namespace: namespace, level: &shared, handlers: &handlerList{}, counters: &counters{}}
+}
+
+func getEnvVarName(namespace string) string {
 	prefix := defaultEnvironmentVariablePrefix
 	if namespace != "" {
 		prefix += "_"
@@ -77,7 +183,14 @@ func getEnvVarLevel(namespace string) string {
 		namespace = strings.Replace(namespace, ".", "_", -1)
 	}
 
-	level := os.Getenv(prefix + namespace)
+	return prefix + namespace
+}
+
+func getEnvVarLevel(namespace string) string {
+	level := getNamespaceEnvLevel(namespace)
+	if level == "" {
+		level = getEnvFilterLevel(namespace)
+	}
 	if level == "" {
 		level = os.Getenv(defaultEnvironmentVariablePrefix)
 	}
@@ -85,6 +198,54 @@ func getEnvVarLevel(namespace string) string {
 	return strings.ToLower(level)
 }
 
+// getNamespaceEnvLevel the environment variable of the namespace or, when it has none, of its nearest ancestor, so
+// "api.auth.token" falls back to "api.auth" and then to "api"
+func getNamespaceEnvLevel(namespace string) string {
+	for ; namespace != ""; namespace = parentNamespace(namespace) {
+		if level := os.Getenv(getEnvVarName(namespace)); level != "" {
+			return level
+		}
+	}
+
+	return ""
+}
+
+// resolveLevel the environment variable of the namespace wins, then the nearest ancestor with an environment variable
+// or a level set by SetLevel, then the first matching pattern of the filter variable, then the namespace level resolver
+// and at last the environment variable of the default namespace. It must be called with loggersLock held
+func resolveLevel(namespace string) Level {
+	for ns := namespace; ns != ""; ns = parentNamespace(ns) {
+		if level := os.Getenv(getEnvVarName(ns)); level != "" {
+			return GetLevelByString(level)
+		}
+		if ancestor, ok := loggers[strings.ToLower(ns)]; ok && ns != namespace && ancestor.explicitLevel {
+			return ancestor.GetLevel()
+		}
+	}
+
+	if level := getEnvFilterLevel(namespace); level != "" {
+		return GetLevelByString(level)
+	}
+
+	if namespaceLevelResolver != nil {
+		if level, ok := namespaceLevelResolver(namespace); ok {
+			return level
+		}
+	}
+
+	return GetLevelByString(getEnvVarLevel(namespace))
+}
+
+// SetNamespaceLevelResolver set a function consulted by Namespace when the namespace has no environment variable, it
+// can assign levels by pattern (e.g. every "vendor." namespace at warn) returning (level, true), or (_, false) to use
+// the default
+func SetNamespaceLevelResolver(resolver func(namespace string) (Level, bool)) {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+
+	namespaceLevelResolver = resolver
+}
+
 func setEnvironmentVariablePrefix(prefix string) error {
 	loggersLock.Lock()
 	defer loggersLock.Unlock()
@@ -114,24 +275,116 @@ func GetDefaultEnvironmentVariablePrefix() string {
 	return defaultEnvironmentVariablePrefix
 }
 
-// GetLevelByString ...
+// ReloadLevelsFromEnv same as ReloadLevels
+//
+// Deprecated: use ReloadLevels
+func ReloadLevelsFromEnv() {
+	ReloadLevels()
+}
+
+// ExportLevelEnv return the environment variables ("NAME=level") that reproduce the current level of every
+// namespace, suitable for exec.Cmd.Env of a child process
+func ExportLevelEnv() []string {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+
+	env := make([]string, 0, len(loggers))
+	for _, logger := range loggers {
+		level := levelToString(logger.GetLevel())
+		if level == "" {
+			level = "none"
+		}
+		env = append(env, getEnvVarName(logger.Namespace)+"="+level)
+	}
+	sort.Strings(env)
+
+	return env
+}
+
+// now it's the clock handlers must use to timestamp records
+func now() time.Time {
+	if TestMode {
+		return testModeTime
+	}
+
+	return time.Now()
+}
+
+func isValidLevelString(level string) bool {
+	_, err := ParseLevel(level)
+	return err == nil
+}
+
+// ParseLevel return the level named by level ("debug", "info", "warn", "error" or "none", in any case), or an error
+// for anything else
+func ParseLevel(level string) (Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	case "none":
+		return LevelNone, nil
+	}
+
+	return LevelInfo, fmt.Errorf("logger: unknown level %q", level)
+}
+
+// GetLevelByString it's ParseLevel falling back to info for unknown levels
 func GetLevelByString(level string) Level {
-	if strings.EqualFold(level, "debug") {
-		return LevelDebug
-	} else if strings.EqualFold(level, "info") {
-		return LevelInfo
-	} else if strings.EqualFold(level, "warn") {
-		return LevelWarn
-	} else if strings.EqualFold(level, "error") {
-		return LevelError
-	} else if strings.EqualFold(level, "none") {
-		return LevelNone
-	} else {
-		return LevelInfo
+	l, _ := ParseLevel(level)
+	return l
+}
+
+// String return the name of the level as accepted by ParseLevel, "Level(N)" when out of range
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	case LevelNone:
+		return "none"
 	}
+
+	return fmt.Sprintf("Level(%d)", uint(l))
 }
 
-// Namespace create a new logger namespace (new instance of logger)
+// MarshalText implements encoding.TextMarshaler, out of range levels are an error
+func (l Level) MarshalText() ([]byte, error) {
+	if l > LevelDebug {
+		return nil, fmt.Errorf("logger: unknown level %d", uint(l))
+	}
+
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+
+	return nil
+}
+
+// Namespace create a new logger namespace (new instance of logger). Dotted namespaces are a hierarchy, a new
+// "app.db.pool" inherits the level set by SetLevel on its nearest ancestor ("app.db", then "app") and the handlers of
+// the nearest ancestor with more than the default handler, unless it has an environment variable of its own
 func Namespace(namespace string) *Logger {
 	loggersLock.Lock()
 	defer loggersLock.Unlock()
@@ -140,113 +393,600 @@ func Namespace(namespace string) *Logger {
 		return logger
 	}
 
-	logger := &Logger{
-		Namespace: namespace,
+	logger := newLogger(namespace, LevelNone)
+
+	envLevel := getEnvVarLevel(namespace)
+	if StrictEnvLevels && envLevel != "" && !isValidLevelString(envLevel) {
+		panic(fmt.Sprintf("logger: invalid level '%s' in environment for namespace '%s'", envLevel, namespace))
 	}
 
-	logger.SetLevel(GetLevelByString(getEnvVarLevel(namespace)))
-	logger.AddHandler(&DefaultHandler{})
+	logger.swapLevel(resolveLevel(namespace))
+	logger.limiter = getEnvVarRate(namespace)
+	var handlers []Interface
+	if namespaceHandlerResolver != nil {
+		handlers = namespaceHandlerResolver(namespace)
+	}
+	if handlers == nil {
+		handlers = ancestorHandlers(namespace)
+	}
+	if handlers != nil {
+		logger.SetHandlers(handlers)
+	} else {
+		logger.AddHandler(&DefaultHandler{})
+	}
 
 	loggers[namespaceLower] = logger
 
 	return logger
 }
 
-// AddHandler ...
-func (logger *Logger) AddHandler(handler Interface) {
-	logger.Handlers = append(logger.Handlers, handler)
+// NewLogger return a logger that isn't registered as a namespace, so it isn't affected by the environment variables,
+// SetLevel of its ancestors nor HTTPHandler, e.g. for tests or libraries that get their handlers from the caller
+func NewLogger(namespace string, level Level, handlers ...Interface) *Logger {
+	logger := newLogger(namespace, level)
+	logger.explicitLevel = true
+	logger.SetHandlers(handlers)
+
+	return logger
+}
+
+// NamespaceWithWriter create a new logger namespace with a single DefaultHandler writing to w, if the namespace
+// already exists it's returned unchanged
+func NamespaceWithWriter(namespace string, w io.Writer, level Level) *Logger {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+	namespaceLower := strings.ToLower(namespace)
+	if logger, ok := loggers[namespaceLower]; ok {
+		return logger
+	}
 
+	logger := newLogger(namespace, LevelNone)
+
+	// it isn't registered yet, so there are neither handlers nor descendants to notify
+	logger.setExplicitLevel(level)
+	logger.AddHandler(&DefaultHandler{Output: w})
+
+	loggers[namespaceLower] = logger
+
+	return logger
+}
+
+// AddHandler initialize handler and then add it, so messages logged meanwhile never reach it uninitialized
+func (logger *Logger) AddHandler(handler Interface) {
 	if initHandler, ok := handler.(InitInterface); ok {
-		initHandler.Init(logger.Namespace, logger.Level)
+		initHandler.Init(logger.Namespace, logger.GetLevel())
+	}
+
+	logger.handlers.lock.Lock()
+	defer logger.handlers.lock.Unlock()
+
+	handlers := make([]Interface, len(logger.handlers.list), len(logger.handlers.list)+1)
+	copy(handlers, logger.handlers.list)
+	logger.handlers.list = append(handlers, handler)
+}
+
+// AtLevel return a logger with the same namespace and handlers, but a level of its own. The returned logger isn't
+// registered, so it doesn't affect the namespace of logger nor the levels listed by HTTPHandler
+func (logger *Logger) AtLevel(level Level) *Logger {
+	derived := logger.derive()
+	own := uint32(level)
+	derived.level = &own
+
+	return derived
+}
+
+// WithHandler add a handler for a scope, call the returned function to remove it again
+func (logger *Logger) WithHandler(handler Interface) (restore func()) {
+	logger.AddHandler(handler)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			logger.RemoveHandler(handler)
+		})
 	}
 }
 
-// SetLevel ...
+// Handlers return a copy of the handlers of logger
+func (logger *Logger) Handlers() []Interface {
+	logger.handlers.lock.Lock()
+	defer logger.handlers.lock.Unlock()
+
+	handlers := make([]Interface, len(logger.handlers.list))
+	copy(handlers, logger.handlers.list)
+
+	return handlers
+}
+
+// RemoveHandlersOfType remove every handler with the same concrete type of sample and return them, closing the
+// removed handlers is up to the caller
+func (logger *Logger) RemoveHandlersOfType(sample Interface) []Interface {
+	sampleType := reflect.TypeOf(sample)
+
+	logger.handlers.lock.Lock()
+	defer logger.handlers.lock.Unlock()
+
+	var kept, removed []Interface
+	for _, handler := range logger.handlers.list {
+		if reflect.TypeOf(unwrapHandler(handler)) == sampleType {
+			removed = append(removed, unwrapHandler(handler))
+		} else {
+			kept = append(kept, handler)
+		}
+	}
+	logger.handlers.list = kept
+
+	return removed
+}
+
+// RemoveAndCloseHandlersOfType same as RemoveHandlersOfType, but it closes the removed handlers that implement
+// io.Closer, returning the first error
+func (logger *Logger) RemoveAndCloseHandlersOfType(sample Interface) error {
+	var firstErr error
+	for _, handler := range logger.RemoveHandlersOfType(sample) {
+		if closer, ok := handler.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// RemoveHandler remove handler, compared by identity, and tell whether it was found. Closing it is up to the caller
+func (logger *Logger) RemoveHandler(handler Interface) bool {
+	logger.handlers.lock.Lock()
+	defer logger.handlers.lock.Unlock()
+
+	for i, h := range logger.handlers.list {
+		if sameHandler(unwrapHandler(h), handler) {
+			handlers := make([]Interface, 0, len(logger.handlers.list)-1)
+			handlers = append(handlers, logger.handlers.list[:i]...)
+			logger.handlers.list = append(handlers, logger.handlers.list[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClearHandlers remove every handler, including the DefaultHandler added by Namespace
+func (logger *Logger) ClearHandlers() {
+	logger.handlers.lock.Lock()
+	defer logger.handlers.lock.Unlock()
+
+	logger.handlers.list = nil
+}
+
+// SetHandlers replace every handler of logger with handlers, that are initialized like in AddHandler
+func (logger *Logger) SetHandlers(handlers []Interface) {
+	for _, handler := range handlers {
+		if initHandler, ok := handler.(InitInterface); ok {
+			initHandler.Init(logger.Namespace, logger.GetLevel())
+		}
+	}
+
+	logger.handlers.lock.Lock()
+	defer logger.handlers.lock.Unlock()
+
+	logger.handlers.list = make([]Interface, len(handlers))
+	copy(logger.handlers.list, handlers)
+}
+
+// handlers return the handlers without copying them, it's safe because the slice is replaced, never changed in place
+func (logger *Logger) currentHandlers() []Interface {
+	logger.handlers.lock.Lock()
+	defer logger.handlers.lock.Unlock()
+
+	return logger.handlers.list
+}
+
+func sameHandler(a, b Interface) bool {
+	typ := reflect.TypeOf(a)
+	if typ != reflect.TypeOf(b) || (typ != nil && !typ.Comparable()) {
+		return false
+	}
+
+	return a == b
+}
+
+// GetLevel ...
+func (logger *Logger) GetLevel() Level {
+	return Level(atomic.LoadUint32(logger.level))
+}
+
+// SetLevel set the level of logger, of the loggers derived from it or that it was derived from, and of its registered
+// descendants that don't have a level of their own
 func (logger *Logger) SetLevel(level Level) {
-	logger.Level = level
+	loggersLock.Lock()
+	changes := logger.setExplicitLevel(level)
+	loggersLock.Unlock()
+
+	notifyLevels(changes)
+}
+
+// setExplicitLevel must be called with loggersLock held, the returned changes must be notified after releasing it
+func (logger *Logger) setExplicitLevel(level Level) []levelChange {
+	logger.explicitLevel = true
 
-	for _, handler := range logger.Handlers {
+	return append([]levelChange{logger.swapLevel(level)}, logger.cascadeLevel()...)
+}
+
+// levelChange it's a level stored by swapLevel that the handlers of the logger weren't told about yet
+type levelChange struct {
+	logger   *Logger
+	oldLevel Level
+	newLevel Level
+}
+
+// swapLevel store level without telling the handlers, so it can be called with loggersLock held. The handlers are told
+// by notify once the lock is released, because they may call Namespace
+func (logger *Logger) swapLevel(level Level) levelChange {
+	oldLevel := Level(atomic.SwapUint32(logger.level, uint32(level)))
+
+	return levelChange{logger: logger, oldLevel: oldLevel, newLevel: level}
+}
+
+func (change levelChange) notify() {
+	for _, handler := range change.logger.currentHandlers() {
 		if initHandler, ok := handler.(InitInterface); ok {
-			initHandler.Init(logger.Namespace, logger.Level)
+			initHandler.Init(change.logger.Namespace, change.newLevel)
+		}
+		if changeHandler, ok := handler.(LevelChangeInterface); ok && change.oldLevel != change.newLevel {
+			changeHandler.OnLevelChange(change.oldLevel, change.newLevel)
 		}
 	}
 }
 
-// Debug ...
-func (logger *Logger) Debug(format string, v ...interface{}) {
-	if logger.Level < LevelDebug {
-		return
+// notifyLevels must be called without loggersLock
+func notifyLevels(changes []levelChange) {
+	for _, change := range changes {
+		change.notify()
 	}
+}
 
-	msg := fmt.Sprintf(format, v...)
-	for _, handler := range logger.Handlers {
-		if debugHandler, ok := handler.(DebugInterface); ok {
-			debugHandler.Debug(msg)
+// CollectMetrics sum the metrics of the handlers that implement MetricsInterface, plus the messages dropped by the
+// logger itself and by SetAsync
+func (logger *Logger) CollectMetrics() HandlerMetrics {
+	var total HandlerMetrics
+	total.Dropped += logger.Dropped()
+	if async := logger.asyncHandler(); async != nil {
+		total.Dropped += async.Dropped()
+	}
+	for _, handler := range logger.Handlers() {
+		if metricsHandler, ok := handler.(MetricsInterface); ok {
+			total.Add(metricsHandler.Metrics())
+		}
+	}
+
+	return total
+}
+
+// Add ...
+func (metrics *HandlerMetrics) Add(other HandlerMetrics) {
+	metrics.Emitted += other.Emitted
+	metrics.Dropped += other.Dropped
+	metrics.Errors += other.Errors
+}
+
+// BytesWritten sum of the bytes written by the handlers that implement BytesCounterInterface
+func (logger *Logger) BytesWritten() uint64 {
+	var total uint64
+	for _, handler := range logger.currentHandlers() {
+		if counterHandler, ok := handler.(BytesCounterInterface); ok {
+			total += counterHandler.BytesWritten()
 		}
 	}
+
+	return total
+}
+
+// ResetBytes ...
+func (logger *Logger) ResetBytes() {
+	for _, handler := range logger.currentHandlers() {
+		if counterHandler, ok := handler.(BytesCounterInterface); ok {
+			counterHandler.ResetBytes()
+		}
+	}
+}
+
+// allow it's the rate limit of the namespace, if there is one
+func (logger *Logger) allow(level Level) bool {
+	if logger.limiter == nil || (level == LevelError && ErrorsBypassRateLimit) {
+		return true
+	}
+
+	return logger.limiter.allow()
+}
+
+// Debug ...
+func (logger *Logger) Debug(format string, v ...interface{}) {
+	logger.log(logger.GetLevel(), LevelDebug, format, v...)
 }
 
 // Info ...
 func (logger *Logger) Info(format string, v ...interface{}) {
-	if logger.Level < LevelInfo {
+	logger.log(logger.GetLevel(), LevelInfo, format, v...)
+}
+
+// Warn ...
+func (logger *Logger) Warn(format string, v ...interface{}) {
+	logger.log(logger.GetLevel(), LevelWarn, format, v...)
+}
+
+// Error ...
+func (logger *Logger) Error(format string, v ...interface{}) {
+	logger.log(logger.GetLevel(), LevelError, format, v...)
+}
+
+// log gate the message by threshold, which is the logger level unless a context overrides it
+func (logger *Logger) log(threshold, level Level, format string, v ...interface{}) {
+	logger.logAs(logger.Namespace, threshold, level, format, v...)
+}
+
+// logAs same as log, but the record is attributed to namespace
+func (logger *Logger) logAs(namespace string, threshold, level Level, format string, v ...interface{}) {
+	if threshold < level {
+		logger.counters.suppress(level)
+		return
+	}
+	if sampler := logger.currentSampler(); sampler != nil && !sampler.Allow(level, format) {
+		logger.counters.drop()
 		return
 	}
 
-	msg := fmt.Sprintf(format, v...)
-	for _, handler := range logger.Handlers {
-		if infoHandler, ok := handler.(InfoInterface); ok {
-			infoHandler.Info(msg)
+	record := Record{
+		Level:     level,
+		Namespace: namespace,
+		Message:   fmt.Sprintf(format, v...),
+		Time:      now(),
+		Fields:    logger.fields,
+	}
+	if atomic.LoadInt32(&logger.reportCaller) == 1 {
+		record.setCaller(callerFrame())
+	}
+	record, ok := logger.runHooks(record)
+	if !ok || !logger.filter(record) {
+		return
+	}
+	if !logger.allow(level) {
+		logger.counters.drop()
+		return
+	}
+	logger.counters.emit(level)
+	logger.observe(level)
+
+	logger.dispatch(record)
+}
+
+// AddFilter add a function that drops the messages it returns false for, before they reach any handler. Filters run
+// after the level check, in the order they were added, and Fatal messages aren't filtered
+func (logger *Logger) AddFilter(filter func(Record) bool) {
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	filters := make([]func(Record) bool, len(logger.filters), len(logger.filters)+1)
+	copy(filters, logger.filters)
+	logger.filters = append(filters, filter)
+}
+
+func (logger *Logger) filter(record Record) bool {
+	logger.handlersLock.Lock()
+	filters := logger.filters
+	logger.handlersLock.Unlock()
+
+	for _, filter := range filters {
+		if !filter(record) {
+			return false
 		}
 	}
+
+	return true
 }
 
-// Warn ...
-func (logger *Logger) Warn(format string, v ...interface{}) {
-	if logger.Level < LevelWarn {
+func (logger *Logger) dispatch(record Record) {
+	handlers := logger.currentHandlers()
+	if async := logger.asyncHandler(); async != nil {
+		async.enqueue(func() {
+			logger.dispatchHandlers(handlers, record)
+		})
 		return
 	}
 
-	msg := fmt.Sprintf(format, v...)
-	for _, handler := range logger.Handlers {
-		if warnHandler, ok := handler.(WarnInterface); ok {
-			warnHandler.Warn(msg)
+	logger.dispatchHandlers(handlers, record)
+}
+
+func (logger *Logger) dispatchHandlers(handlers []Interface, record Record) {
+	if !logger.ConcurrentDispatch || len(handlers) < 2 {
+		for _, handler := range handlers {
+			dispatchTo(handler, record)
 		}
+		return
+	}
+
+	// waiting for every handler keeps the messages of a goroutine in order on each handler
+	wait := sync.WaitGroup{}
+	wait.Add(len(handlers))
+	for _, handler := range handlers {
+		go func(handler Interface) {
+			defer wait.Done()
+			dispatchTo(handler, record)
+		}(handler)
 	}
+	wait.Wait()
 }
 
-// Error ...
-func (logger *Logger) Error(format string, v ...interface{}) {
-	if logger.Level < LevelError {
+// dispatchTo prefer RecordInterface, then the fields interfaces, the handlers that only implement the level
+// interfaces get the message alone
+func dispatchTo(handler Interface, record Record) {
+	if recordHandler, ok := handler.(RecordInterface); ok {
+		recordHandler.Log(record)
 		return
 	}
 
-	msg := fmt.Sprintf(format, v...)
-	for _, handler := range logger.Handlers {
-		if errorHandler, ok := handler.(ErrorInterface); ok {
-			errorHandler.Error(msg)
+	switch record.Level {
+	case LevelDebug:
+		if debugHandler, ok := handler.(DebugFieldsInterface); ok {
+			debugHandler.DebugFields(record.Message, record.Fields)
+		} else if debugHandler, ok := handler.(DebugInterface); ok {
+			debugHandler.Debug(record.callerMessage())
+		}
+	case LevelInfo:
+		if infoHandler, ok := handler.(InfoFieldsInterface); ok {
+			infoHandler.InfoFields(record.Message, record.Fields)
+		} else if infoHandler, ok := handler.(InfoInterface); ok {
+			infoHandler.Info(record.callerMessage())
+		}
+	case LevelWarn:
+		if warnHandler, ok := handler.(WarnFieldsInterface); ok {
+			warnHandler.WarnFields(record.Message, record.Fields)
+		} else if warnHandler, ok := handler.(WarnInterface); ok {
+			warnHandler.Warn(record.callerMessage())
+		}
+	case LevelError:
+		if errorHandler, ok := handler.(ErrorFieldsInterface); ok {
+			errorHandler.ErrorFields(record.Message, record.Fields)
+		} else if errorHandler, ok := handler.(ErrorInterface); ok {
+			errorHandler.Error(record.callerMessage())
 		}
 	}
 }
 
-// Fatal ...
+// callerMessage it's the message for handlers without fields, prefixed by the call site when there is one
+func (record Record) callerMessage() string {
+	if record.Caller == "" {
+		return record.Message
+	}
+
+	return record.Caller + " " + record.Message
+}
+
+// DebugNS log a debug message attributed to namespace instead of the namespace of logger, only handlers that
+// implement RecordInterface see the overridden namespace
+func (logger *Logger) DebugNS(namespace string, format string, v ...interface{}) {
+	logger.logAs(namespace, logger.GetLevel(), LevelDebug, format, v...)
+}
+
+// InfoNS same as DebugNS at info level
+func (logger *Logger) InfoNS(namespace string, format string, v ...interface{}) {
+	logger.logAs(namespace, logger.GetLevel(), LevelInfo, format, v...)
+}
+
+// WarnNS same as DebugNS at warn level
+func (logger *Logger) WarnNS(namespace string, format string, v ...interface{}) {
+	logger.logAs(namespace, logger.GetLevel(), LevelWarn, format, v...)
+}
+
+// ErrorNS same as DebugNS at error level
+func (logger *Logger) ErrorNS(namespace string, format string, v ...interface{}) {
+	logger.logAs(namespace, logger.GetLevel(), LevelError, format, v...)
+}
+
+// ErrIf log an error only when err is not nil, the error is appended to the message and returned
+func (logger *Logger) ErrIf(err error, format string, v ...interface{}) error {
+	if err != nil {
+		logger.Error("%s: %v", fmt.Sprintf(format, v...), err)
+	}
+
+	return err
+}
+
+// Fatal log at fatal level, run the fatal hooks and exit with code 1 through the exit function (see SetExitFunc)
 func (logger *Logger) Fatal(format string, v ...interface{}) {
-	if logger.Level < LevelError {
+	if logger.GetLevel() < LevelFatal {
 		return
 	}
 
+	record := logger.logFatal(fmt.Sprintf(format, v...))
+	runFatalHooks(record)
+	logger.exit(1)
+}
+
+// Panic log at fatal level and panic with the message instead of exiting, so deferred functions run and the panic
+// can be recovered. The fatal hooks don't run
+func (logger *Logger) Panic(format string, v ...interface{}) {
 	msg := fmt.Sprintf(format, v...)
-	for _, handler := range logger.Handlers {
-		if fatalHandler, ok := handler.(FatalInterface); ok {
-			fatalHandler.Fatal(msg)
+	if logger.GetLevel() >= LevelFatal {
+		logger.logFatal(msg)
+	}
+
+	panic(msg)
+}
+
+// logFatal send msg to the handlers right away, even when the logger is async, and flush them
+func (logger *Logger) logFatal(msg string) Record {
+	logger.counters.emit(LevelFatal)
+
+	record := Record{
+		Level:     LevelFatal,
+		Namespace: logger.Namespace,
+		Message:   msg,
+		Time:      now(),
+		Fields:    logger.fields,
+	}
+	if atomic.LoadInt32(&logger.reportCaller) == 1 {
+		record.setCaller(callerFrame())
+	}
+	if hooked, ok := logger.runHooks(record); ok {
+		record = hooked
+	}
+	logger.Flush()
+	for _, handler := range logger.currentHandlers() {
+		if fatalHandler, ok := handler.(FatalFieldsInterface); ok {
+			fatalHandler.FatalFields(record.Message, record.Fields)
+		} else if fatalHandler, ok := handler.(FatalInterface); ok {
+			fatalHandler.Fatal(record.callerMessage())
+		}
+		if flushHandler, ok := handler.(FlushInterface); ok {
+			flushHandler.Flush()
 		}
 	}
-	os.Exit(1)
+
+	return record
+}
+
+// SetExitFunc replace the function called by Fatal to exit, nil goes back to ExitFunc
+func (logger *Logger) SetExitFunc(exit func(code int)) {
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	logger.exitFunc = exit
+}
+
+func (logger *Logger) exit(code int) {
+	exit := logger.currentExitFunc()
+	if exit == nil {
+		exit = ExitFunc
+	}
+	exit(code)
+}
+
+func (logger *Logger) currentExitFunc() func(code int) {
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	return logger.exitFunc
+}
+
+// Printf log at Info level, it's here to make *Logger a near drop-in replacement of *log.Logger
+func (logger *Logger) Printf(format string, v ...interface{}) {
+	logger.Info(format, v...)
+}
+
+// Println log at Info level with the operands formatted like fmt.Sprintln
+func (logger *Logger) Println(v ...interface{}) {
+	logger.Info("%s", strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+// Print log at Info level with the operands formatted like fmt.Sprint
+func (logger *Logger) Print(v ...interface{}) {
+	logger.Info("%s", fmt.Sprint(v...))
 }
 
-// Write ...
+// Write log b at Info level, see Writer for other levels
 func (logger *Logger) Write(b []byte) (int, error) {
-	logger.Info("%s", strings.TrimRight(string(b), "\n"))
-	return len(b), nil
+	return logger.Writer(LevelInfo).Write(b)
 }
 
 // AddHandler ...
@@ -279,7 +1019,17 @@ func Error(format string, v ...interface{}) {
 	DefaultLogger.Error(format, v...)
 }
 
+// ErrIf ...
+func ErrIf(err error, format string, v ...interface{}) error {
+	return DefaultLogger.ErrIf(err, format, v...)
+}
+
 // Fatal ...
 func Fatal(format string, v ...interface{}) {
 	DefaultLogger.Fatal(format, v...)
 }
+
+// Panic ...
+func Panic(format string, v ...interface{}) {
+	DefaultLogger.Panic(format, v...)
+}
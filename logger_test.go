@@ -1,12 +1,57 @@
 package logger_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/NeowayLabs/logger"
 )
 
+type safeBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+type captureHandler struct {
+	lock sync.Mutex
+	msgs []string
+}
+
+func (handler *captureHandler) Info(msg string) {
+	handler.lock.Lock()
+	handler.msgs = append(handler.msgs, msg)
+	handler.lock.Unlock()
+}
+
+func (handler *captureHandler) Error(msg string) {
+	handler.Info(msg)
+}
+
 func TestCanCreateSameNamespaceOnDifferentGoRoutines(t *testing.T) {
 	const concurrency = 10000
 
@@ -22,3 +67,1101 @@ func TestCanCreateSameNamespaceOnDifferentGoRoutines(t *testing.T) {
 	}
 	wait.Wait()
 }
+
+func TestNamespacePanicsOnInvalidEnvLevelWhenStrict(t *testing.T) {
+	os.Setenv("SEVERINO_LOGGER_STRICT_TEST", "debgu")
+	logger.StrictEnvLevels = true
+	defer func() {
+		os.Unsetenv("SEVERINO_LOGGER_STRICT_TEST")
+		logger.StrictEnvLevels = false
+		if recover() == nil {
+			t.Fatal("expected Namespace to panic on invalid env level")
+		}
+	}()
+
+	logger.Namespace("strict-test")
+}
+
+func TestWithHandlerIsRemovedOnRestore(t *testing.T) {
+	log := logger.Namespace("with-handler-test")
+	capture := &captureHandler{}
+
+	restore := log.WithHandler(capture)
+	log.Info("captured")
+	restore()
+	log.Info("not captured")
+
+	if len(capture.msgs) != 1 || capture.msgs[0] != "captured" {
+		t.Fatal("expected only the scoped message to be captured, got", capture.msgs)
+	}
+	if len(log.Handlers()) != 1 {
+		t.Fatal("expected the default handler to be left, got", len(log.Handlers()))
+	}
+}
+
+func TestNamespaceWithWriterWritesToWriter(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("writer-test", &buf, logger.LevelDebug)
+
+	log.Debug("number=%d", 1)
+	log.Error("number=%d", 2)
+
+	expected := "<writer-test> [DEBUG] number=1\n<writer-test> [ERROR] number=2\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestBytesWrittenCountsHandlerOutput(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("bytes-test", &buf, logger.LevelInfo)
+
+	log.Info("hello")
+	log.Debug("discarded")
+	if log.BytesWritten() != uint64(buf.Len()) {
+		t.Fatalf("expected %d bytes, got %d", buf.Len(), log.BytesWritten())
+	}
+
+	log.ResetBytes()
+	if log.BytesWritten() != 0 {
+		t.Fatal("expected counter to be reset, got", log.BytesWritten())
+	}
+}
+
+func TestErrIfOnlyLogsNonNilErrors(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("errif-test", &buf, logger.LevelInfo)
+
+	if err := log.ErrIf(nil, "nothing"); err != nil || buf.Len() != 0 {
+		t.Fatal("expected nil error to be a no-op, got", buf.String())
+	}
+
+	err := errors.New("boom")
+	if log.ErrIf(err, "saving %s", "user") != err {
+		t.Fatal("expected the error to be returned")
+	}
+	if buf.String() != "<errif-test> [ERROR] saving user: boom\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+type levelChangeHandler struct {
+	changes [][2]logger.Level
+}
+
+func (handler *levelChangeHandler) OnLevelChange(oldLevel, newLevel logger.Level) {
+	handler.changes = append(handler.changes, [2]logger.Level{oldLevel, newLevel})
+}
+
+func TestSetLevelNotifiesLevelChanges(t *testing.T) {
+	log := logger.NamespaceWithWriter("level-change-test", &bytes.Buffer{}, logger.LevelInfo)
+	handler := &levelChangeHandler{}
+	log.AddHandler(handler)
+
+	log.SetLevel(logger.LevelInfo)
+	log.SetLevel(logger.LevelDebug)
+
+	if len(handler.changes) != 1 || handler.changes[0] != [2]logger.Level{logger.LevelInfo, logger.LevelDebug} {
+		t.Fatal("expected a single info to debug change, got", handler.changes)
+	}
+}
+
+type registeringHandler struct{}
+
+func (registeringHandler) OnLevelChange(oldLevel, newLevel logger.Level) {
+	logger.Namespace("level-change-registered")
+}
+
+func TestLevelChangeHandlersCanRegisterNamespaces(t *testing.T) {
+	log := logger.NamespaceWithWriter("level-change-registers", &bytes.Buffer{}, logger.LevelInfo)
+	log.AddHandler(registeringHandler{})
+
+	done := make(chan struct{})
+	go func() {
+		log.SetLevel(logger.LevelDebug)
+		logger.ReloadLevels()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected SetLevel to return when a handler registers a namespace")
+	}
+}
+
+func TestRemoveHandlersOfType(t *testing.T) {
+	log := logger.NamespaceWithWriter("remove-type-test", &bytes.Buffer{}, logger.LevelInfo)
+	log.AddHandler(&captureHandler{})
+	log.AddHandler(&captureHandler{})
+
+	removed := log.RemoveHandlersOfType(&captureHandler{})
+	if len(removed) != 2 {
+		t.Fatal("expected 2 handlers removed, got", len(removed))
+	}
+
+	handlers := log.Handlers()
+	if len(handlers) != 1 {
+		t.Fatal("expected only the default handler to be left, got", len(handlers))
+	}
+	if _, ok := handlers[0].(*logger.DefaultHandler); !ok {
+		t.Fatalf("expected the default handler to be left, got %T", handlers[0])
+	}
+}
+
+func TestExportLevelEnv(t *testing.T) {
+	logger.NamespaceWithWriter("vendor.export-test", &bytes.Buffer{}, logger.LevelDebug)
+
+	for _, env := range logger.ExportLevelEnv() {
+		if env == "SEVERINO_LOGGER_VENDOR_EXPORT_TEST=debug" {
+			return
+		}
+	}
+	t.Fatal("expected export-test level in", logger.ExportLevelEnv())
+}
+
+func TestNamespaceInheritsEnvLevelFromAncestors(t *testing.T) {
+	defer os.Unsetenv("SEVERINO_LOGGER_INHERIT")
+	defer os.Unsetenv("SEVERINO_LOGGER_INHERIT_OWN")
+	os.Setenv("SEVERINO_LOGGER_INHERIT", "debug")
+	os.Setenv("SEVERINO_LOGGER_INHERIT_OWN", "error")
+
+	if level := logger.Namespace("inherit.auth.token").GetLevel(); level != logger.LevelDebug {
+		t.Fatal("expected level of the ancestor, got", level)
+	}
+	if level := logger.Namespace("inherit.own.child").GetLevel(); level != logger.LevelError {
+		t.Fatal("expected level of the nearest ancestor, got", level)
+	}
+
+	explicit := logger.Namespace("inherit.explicit")
+	explicit.SetLevel(logger.LevelWarn)
+	logger.ReloadLevels()
+	if explicit.GetLevel() != logger.LevelWarn {
+		t.Fatal("expected explicit level to be kept over the ancestor, got", explicit.GetLevel())
+	}
+}
+
+func TestEnvFilterPatterns(t *testing.T) {
+	defer os.Unsetenv("SEVERINO_LOGGER__FILTER")
+	os.Setenv("SEVERINO_LOGGER__FILTER", "pattern.db.*=debug, pattern.http=warn,pattern.*=error")
+
+	if level := logger.Namespace("pattern.db.pool").GetLevel(); level != logger.LevelDebug {
+		t.Fatal("expected debug from db.*, got", level)
+	}
+	if level := logger.Namespace("Pattern.HTTP").GetLevel(); level != logger.LevelWarn {
+		t.Fatal("expected warn from http, got", level)
+	}
+	if level := logger.Namespace("pattern.queue").GetLevel(); level != logger.LevelError {
+		t.Fatal("expected error from the catch-all, got", level)
+	}
+
+	os.Setenv("SEVERINO_LOGGER__FILTER", "pattern.queue=info")
+	logger.ReloadLevels()
+	if level := logger.Namespace("pattern.queue").GetLevel(); level != logger.LevelInfo {
+		t.Fatal("expected the pattern to be reloaded, got", level)
+	}
+
+	defer os.Unsetenv("SEVERINO_LOGGER_FILTER")
+	os.Setenv("SEVERINO_LOGGER_FILTER", "debug")
+	if level := logger.Namespace("filter").GetLevel(); level != logger.LevelDebug {
+		t.Fatal("expected a namespace called filter to have its own variable, got", level)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []logger.Level{logger.LevelNone, logger.LevelFatal, logger.LevelError, logger.LevelWarn, logger.LevelInfo, logger.LevelDebug} {
+		parsed, err := logger.ParseLevel(strings.ToUpper(level.String()))
+		if err != nil || parsed != level {
+			t.Fatalf("expected %s to round-trip, got %s %v", level, parsed, err)
+		}
+	}
+
+	if _, err := logger.ParseLevel("debgu"); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+	if logger.GetLevelByString("debgu") != logger.LevelInfo {
+		t.Fatal("expected GetLevelByString to fall back to info")
+	}
+	if logger.Level(42).String() != "Level(42)" {
+		t.Fatal("unexpected out of range level", logger.Level(42).String())
+	}
+}
+
+func TestLevelTextMarshaling(t *testing.T) {
+	var config struct {
+		Level logger.Level `json:"level"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"level":"warn"}`), &config); err != nil || config.Level != logger.LevelWarn {
+		t.Fatal("expected warn, got", config.Level, err)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"loud"}`), &config); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+
+	out, err := json.Marshal(config)
+	if err != nil || string(out) != `{"level":"warn"}` {
+		t.Fatalf("unexpected %s %v", out, err)
+	}
+}
+
+func TestReloadLevelsFromEnvKeepsExplicitLevels(t *testing.T) {
+	defer os.Unsetenv("SEVERINO_LOGGER_RELOAD_ENV")
+	defer os.Unsetenv("SEVERINO_LOGGER_RELOAD_EXPLICIT")
+
+	fromEnv := logger.Namespace("reload-env")
+	explicit := logger.Namespace("reload-explicit.child")
+	explicit.SetLevel(logger.LevelWarn)
+
+	os.Setenv("SEVERINO_LOGGER_RELOAD_ENV", "debug")
+	os.Setenv("SEVERINO_LOGGER_RELOAD_EXPLICIT", "debug")
+	logger.ReloadLevelsFromEnv()
+
+	if fromEnv.GetLevel() != logger.LevelDebug {
+		t.Fatal("expected level from env to be reloaded, got", fromEnv.GetLevel())
+	}
+	if explicit.GetLevel() != logger.LevelWarn {
+		t.Fatal("expected explicit level to be kept over the ancestor, got", explicit.GetLevel())
+	}
+}
+
+func TestReloadLevelsSkipsUnsetNamespaces(t *testing.T) {
+	defer os.Unsetenv("SEVERINO_LOGGER_RELOAD_SET")
+
+	set := logger.Namespace("reload-set")
+	set.SetLevel(logger.LevelWarn)
+	unset := logger.Namespace("reload-unset")
+	unset.SetLevel(logger.LevelError)
+
+	os.Setenv("SEVERINO_LOGGER_RELOAD_SET", "debug")
+	logger.ReloadLevels()
+	logger.ReloadLevels()
+
+	if set.GetLevel() != logger.LevelDebug {
+		t.Fatal("expected level from env to be applied, got", set.GetLevel())
+	}
+	if unset.GetLevel() != logger.LevelError {
+		t.Fatal("expected level without env to be kept, got", unset.GetLevel())
+	}
+}
+
+type levelNotifier chan logger.Level
+
+func (notifier levelNotifier) OnLevelChange(oldLevel, newLevel logger.Level) {
+	notifier <- newLevel
+}
+
+func TestWatchIntervalReloadsLevels(t *testing.T) {
+	defer os.Unsetenv("SEVERINO_LOGGER_RELOAD_TICKER")
+	notifier := make(levelNotifier, 1)
+	logger.Namespace("reload-ticker").AddHandler(notifier)
+
+	stop := logger.WatchInterval(time.Millisecond)
+	defer stop()
+	os.Setenv("SEVERINO_LOGGER_RELOAD_TICKER", "error")
+
+	select {
+	case level := <-notifier:
+		if level != logger.LevelError {
+			t.Fatal("expected the level from env, got", level)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the level to be reloaded")
+	}
+}
+
+func TestWatchIntervalClampsTheInterval(t *testing.T) {
+	logger.WatchInterval(0)()
+	logger.WatchInterval(-time.Second)()
+}
+
+func TestPrintFamilyLogsAtInfo(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("print-test", &buf, logger.LevelInfo)
+
+	log.Printf("number=%d", 1)
+	log.Println("number", 2)
+	log.Print("number", 3)
+
+	expected := "<print-test> [INFO] number=1\n<print-test> [INFO] number 2\n<print-test> [INFO] number3\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestHeartbeatLogsUntilStopped(t *testing.T) {
+	var buf safeBuffer
+	log := logger.NamespaceWithWriter("heartbeat-test", &buf, logger.LevelInfo)
+	log.Warn("before")
+
+	stop := log.Heartbeat(time.Millisecond, logger.LevelInfo)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	stop()
+
+	output := buf.String()
+	if !strings.Contains(output, "[INFO] heartbeat uptime=") || !strings.Contains(output, "warn=1") {
+		t.Fatalf("expected heartbeat with counts, got %q", output)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if buf.String() != output {
+		t.Fatal("expected no heartbeat after stop")
+	}
+}
+
+func TestHeartbeatClampsItsArguments(t *testing.T) {
+	var buf safeBuffer
+	log := logger.NamespaceWithWriter("heartbeat-clamp-test", &buf, logger.LevelInfo)
+
+	log.Heartbeat(0, logger.LevelInfo)()
+	stop := log.Heartbeat(time.Millisecond, logger.LevelNone)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	if !strings.Contains(buf.String(), "[ERROR] heartbeat uptime=") {
+		t.Fatalf("expected heartbeat at error, got %q", buf.String())
+	}
+}
+
+func TestNamespaceLevelResolver(t *testing.T) {
+	logger.SetNamespaceLevelResolver(func(namespace string) (logger.Level, bool) {
+		if strings.HasPrefix(namespace, "vendor.") {
+			return logger.LevelWarn, true
+		}
+		return 0, false
+	})
+	defer logger.SetNamespaceLevelResolver(nil)
+
+	os.Setenv("SEVERINO_LOGGER_VENDOR_RESOLVER_ENV", "debug")
+	defer os.Unsetenv("SEVERINO_LOGGER_VENDOR_RESOLVER_ENV")
+
+	if level := logger.Namespace("vendor.resolver").GetLevel(); level != logger.LevelWarn {
+		t.Fatal("expected level from resolver, got", level)
+	}
+	if level := logger.Namespace("vendor.resolver-env").GetLevel(); level != logger.LevelDebug {
+		t.Fatal("expected env var to win over resolver, got", level)
+	}
+	if level := logger.Namespace("resolver-fallthrough").GetLevel(); level != logger.LevelInfo {
+		t.Fatal("expected default level, got", level)
+	}
+}
+
+func TestNamespaceRateFromEnv(t *testing.T) {
+	os.Setenv("SEVERINO_LOGGER_RATE_TEST__RATE", "2/h")
+	defer os.Unsetenv("SEVERINO_LOGGER_RATE_TEST__RATE")
+
+	log := logger.Namespace("rate-test")
+	capture := &captureHandler{}
+	log.AddHandler(capture)
+
+	for i := 0; i < 5; i++ {
+		log.Info("info %d", i)
+	}
+	log.Error("error")
+
+	if len(capture.msgs) != 3 || capture.msgs[2] != "error" {
+		t.Fatal("expected 2 infos and the error, got", capture.msgs)
+	}
+	if level := logger.Namespace("rate-test.rate").GetLevel(); level != logger.GetLevelByString(os.Getenv("SEVERINO_LOGGER")) {
+		t.Fatal("expected the rate not to be taken for a level, got", level)
+	}
+}
+
+func TestAutoNamespaceUsesCallerPackage(t *testing.T) {
+	log := logger.AutoNamespace()
+	if log.Namespace != "github.com.NeowayLabs.logger_test" {
+		t.Fatal("expected namespace from the test package, got", log.Namespace)
+	}
+	if logger.Namespace(log.Namespace) != log {
+		t.Fatal("expected AutoNamespace to register the namespace")
+	}
+}
+
+func TestContextLevelOverridesLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("context-level-test", &buf, logger.LevelInfo)
+	ctx := logger.ContextWithLevel(context.Background(), logger.LevelDebug)
+
+	log.Debug("discarded")
+	log.DebugCtx(nil, "discarded")
+	log.DebugCtx(ctx, "traced")
+
+	if buf.String() != "<context-level-test> [DEBUG] traced\n" {
+		t.Fatalf("expected only the traced message, got %q", buf.String())
+	}
+}
+
+func TestDerivedLoggersShareLevelAndHandlers(t *testing.T) {
+	log := logger.NamespaceWithWriter("derived-share", &bytes.Buffer{}, logger.LevelInfo)
+	child := log.With("component", "x").WithCaller()
+
+	log.SetLevel(logger.LevelDebug)
+	memory := logger.NewMemoryHandler()
+	log.AddHandler(memory)
+	child.Debug("after")
+
+	if child.GetLevel() != logger.LevelDebug || !memory.Contains(logger.LevelDebug, "after") {
+		t.Fatal("expected the derived logger to follow its parent, got", child.GetLevel(), memory.Entries())
+	}
+}
+
+func TestBoundFieldsFollowTheAdminEndpoint(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	log := logger.Namespace("bound-fields")
+	log.SetHandlers([]logger.Interface{memory})
+	log.SetLevel(logger.LevelInfo)
+	reqLog := log.With("request_id", 42)
+
+	req := httptest.NewRequest("PUT", "/logger/bound-fields", strings.NewReader(`{"level":"debug"}`))
+	logger.AdminHandler().ServeHTTP(httptest.NewRecorder(), req)
+	reqLog.Debug("verbose")
+
+	entries := memory.Entries()
+	if len(entries) != 1 || entries[0].Fields["request_id"] != 42 || entries[0].Namespace != "bound-fields" {
+		t.Fatal("expected the bound logger to log at the new level with its fields, got", entries)
+	}
+}
+
+func TestAtLevelDoesNotChangeParent(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("at-level-test", &buf, logger.LevelInfo)
+
+	verbose := log.AtLevel(logger.LevelDebug)
+	verbose.Debug("verbose")
+	log.Debug("discarded")
+
+	if log.GetLevel() != logger.LevelInfo || logger.Namespace("at-level-test") != log {
+		t.Fatal("expected parent logger to be untouched")
+	}
+	if buf.String() != "<at-level-test> [DEBUG] verbose\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+type slowHandler struct{}
+
+func (handler slowHandler) Info(msg string) {
+	time.Sleep(time.Millisecond)
+}
+
+func benchmarkDispatch(b *testing.B, concurrent bool) {
+	log := logger.NewLogger("", logger.LevelInfo)
+	log.ConcurrentDispatch = concurrent
+	for i := 0; i < 4; i++ {
+		log.AddHandler(slowHandler{})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("message")
+	}
+}
+
+func BenchmarkSequentialDispatch(b *testing.B) {
+	benchmarkDispatch(b, false)
+}
+
+func BenchmarkConcurrentDispatch(b *testing.B) {
+	benchmarkDispatch(b, true)
+}
+
+func TestFiltersDropRecords(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("filter-test", &buf, logger.LevelInfo)
+	log.AddFilter(func(record logger.Record) bool {
+		return !strings.Contains(record.Message, "/healthz")
+	})
+	log.AddFilter(func(record logger.Record) bool {
+		return record.Namespace == "filter-test" && record.Level == logger.LevelInfo
+	})
+
+	log.Info("GET /healthz")
+	log.Info("GET /users")
+	log.Warn("not info")
+
+	if buf.String() != "<filter-test> [INFO] GET /users\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestOnRateExceededFiresOncePerWindow(t *testing.T) {
+	log := logger.NamespaceWithWriter("rate-exceeded-test", &bytes.Buffer{}, logger.LevelInfo)
+
+	var fired []int
+	log.OnRateExceeded(logger.LevelError, 3, time.Hour, func(count int) {
+		fired = append(fired, count)
+	})
+
+	for i := 0; i < 10; i++ {
+		log.Error("storm")
+		log.Info("not monitored")
+	}
+
+	if len(fired) != 1 || fired[0] != 4 {
+		t.Fatal("expected a single callback with count 4, got", fired)
+	}
+}
+
+func TestInfoNSOverridesNamespace(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("ns-test", &buf, logger.LevelInfo)
+	capture := &captureHandler{}
+	log.AddHandler(capture)
+
+	log.InfoNS("billing", "invoice %d", 1)
+	log.Info("own namespace")
+
+	expected := "<billing> [INFO] invoice 1\n<ns-test> [INFO] own namespace\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+	if len(capture.msgs) != 2 || capture.msgs[0] != "invoice 1" {
+		t.Fatal("expected plain handlers to get the message alone, got", capture.msgs)
+	}
+}
+
+type fieldsHandler struct {
+	msgs   []string
+	fields []map[string]interface{}
+}
+
+func (handler *fieldsHandler) Info(msg string) {
+	handler.msgs = append(handler.msgs, msg)
+}
+
+func (handler *fieldsHandler) InfoFields(msg string, fields map[string]interface{}) {
+	handler.msgs = append(handler.msgs, msg)
+	handler.fields = append(handler.fields, fields)
+}
+
+func TestWithFieldsMergesAndIsNotRegistered(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("fields-test", &buf, logger.LevelInfo)
+	handler := &fieldsHandler{}
+	log.AddHandler(handler)
+
+	requestLog := log.WithFields(map[string]interface{}{"request_id": "abc", "user": "bob"})
+	requestLog.With("user", "alice").With("status", 200).Info("request done")
+
+	if logger.Namespace("fields-test") != log {
+		t.Fatal("expected derived loggers not to replace the namespace")
+	}
+	if len(handler.fields) != 1 || len(handler.fields[0]) != 3 || handler.fields[0]["user"] != "alice" {
+		t.Fatal("expected merged fields, got", handler.fields)
+	}
+	if buf.String() != "<fields-test> [INFO] request done request_id=abc status=200 user=alice\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+type requestIDKey struct{}
+
+func TestContextExtractorsAttachFields(t *testing.T) {
+	logger.RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return map[string]interface{}{"request_id": id, "source": "first"}
+		}
+		return nil
+	})
+	logger.RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		if ctx.Value(requestIDKey{}) == nil {
+			return nil
+		}
+		return map[string]interface{}{"source": "second"}
+	})
+
+	var buf bytes.Buffer
+	log := logger.NamespaceWithWriter("extractor-test", &buf, logger.LevelInfo)
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+
+	log.InfoCtx(ctx, "with context")
+	log.WithContext(ctx).Info("derived")
+	log.InfoCtx(nil, "nil context")
+
+	expected := "<extractor-test> [INFO] with context request_id=abc source=second\n" +
+		"<extractor-test> [INFO] derived request_id=abc source=second\n" +
+		"<extractor-test> [INFO] nil context\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+type countingStringer struct {
+	calls *int
+}
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "formatted"
+}
+
+func TestRateSamplerIsPerLevelAndSkipsFormatting(t *testing.T) {
+	capture := &captureHandler{}
+	log := (logger.NewLogger("", logger.LevelDebug, capture)).
+		WithSampler(logger.NewRateSampler(10))
+
+	var calls int
+	for i := 0; i < 100; i++ {
+		log.Info("flood %s", countingStringer{&calls})
+	}
+	log.Error("rare")
+
+	if len(capture.msgs) != 11 || capture.msgs[10] != "rare" {
+		t.Fatal("expected 1 of every 10 infos and the error, got", len(capture.msgs), capture.msgs)
+	}
+	if calls != 10 {
+		t.Fatal("expected only kept messages to be formatted, got", calls)
+	}
+}
+
+func TestPerSecondSampler(t *testing.T) {
+	sampler := logger.NewPerSecondSampler(5)
+
+	var allowed int
+	for i := 0; i < 20; i++ {
+		if sampler.Allow(logger.LevelDebug, "flood") {
+			allowed++
+		}
+	}
+
+	if allowed != 5 || !sampler.Allow(logger.LevelError, "rare") {
+		t.Fatal("expected a burst of 5 debug messages and the error, got", allowed)
+	}
+}
+
+func TestRemoveClearAndSetHandlers(t *testing.T) {
+	log := logger.Namespace("remove-handlers")
+	first, second := logger.NewMemoryHandler(), logger.NewMemoryHandler()
+	log.SetHandlers([]logger.Interface{first, second})
+
+	var wait sync.WaitGroup
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		for i := 0; i < 100; i++ {
+			log.Info("concurrent")
+		}
+	}()
+
+	if !log.RemoveHandler(first) || log.RemoveHandler(first) {
+		t.Fatal("expected the handler to be removed once")
+	}
+	wait.Wait()
+
+	if handlers := log.Handlers(); len(handlers) != 1 || handlers[0] != second {
+		t.Fatal("expected only the second handler to be left, got", handlers)
+	}
+
+	log.ClearHandlers()
+	log.Info("dropped")
+	if len(log.Handlers()) != 0 || second.Contains(logger.LevelInfo, "dropped") {
+		t.Fatal("expected no handlers")
+	}
+}
+
+func TestWithCallerReportsCallSite(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	capture := &captureHandler{}
+	log := (logger.NewLogger("", logger.LevelInfo, memory, capture)).WithCaller()
+	std := stdlog.New(log, "", 0)
+
+	_, _, line, _ := runtime.Caller(0)
+	log.Info("direct")
+	std.Print("through writer")
+
+	entries := memory.Entries()
+	for i, entry := range entries {
+		expected := fmt.Sprintf("logger_test.go:%d", line+1+i)
+		if entry.Fields["caller"] != expected {
+			t.Fatalf("expected caller %s, got %v", expected, entry.Fields["caller"])
+		}
+	}
+	if len(capture.msgs) != 2 || capture.msgs[0] != fmt.Sprintf("logger_test.go:%d direct", line+1) {
+		t.Fatal("expected the call site prefixed for plain handlers, got", capture.msgs)
+	}
+}
+
+func TestNewContextCarriesLogger(t *testing.T) {
+	if logger.FromContext(context.Background()) != logger.DefaultLogger {
+		t.Fatal("expected DefaultLogger for a context without logger")
+	}
+
+	memory := logger.NewMemoryHandler()
+	reqLog := (logger.NewLogger("request", logger.LevelInfo, memory)).
+		With("request_id", "abc")
+	ctx := logger.NewContext(context.Background(), reqLog)
+
+	if logger.FromContext(ctx) != reqLog {
+		t.Fatal("expected the logger carried by the context")
+	}
+
+	logger.InfoCtx(ctx, "handled")
+	entries := memory.Entries()
+	if len(entries) != 1 || entries[0].Fields["request_id"] != "abc" {
+		t.Fatalf("expected the package-level function to use the context logger, got %+v", entries)
+	}
+}
+
+func TestAddHandlerWithLevel(t *testing.T) {
+	file, console := logger.NewMemoryHandler(), logger.NewMemoryHandler()
+	log := logger.NewLogger("handler-level", logger.LevelDebug)
+	log.AddHandler(file)
+	log.AddHandlerWithLevel(console, logger.LevelError)
+
+	log.Debug("details")
+	log.Error("broken")
+
+	if len(file.Entries()) != 2 || len(console.Entries()) != 1 || !console.Contains(logger.LevelError, "broken") {
+		t.Fatal("expected only errors on the console, got", console.Entries())
+	}
+
+	if !log.RemoveHandler(console) || len(log.Handlers()) != 1 {
+		t.Fatal("expected the handler with level to be removed")
+	}
+}
+
+func TestSetAsyncDispatchesInBackground(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	log := logger.NewLogger("async-mode", logger.LevelInfo, memory)
+	log.SetAsync(16, false)
+
+	derived := log.With("request_id", "abc")
+	for i := 0; i < 100; i++ {
+		derived.Info("message %d", i)
+	}
+	log.Flush()
+
+	entries := memory.Entries()
+	if len(entries) != 100 || entries[99].Msg != "message 99" || entries[99].Fields["request_id"] != "abc" {
+		t.Fatal("expected every message after Flush, got", len(entries))
+	}
+
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+	derived.Info("synchronous")
+	if !memory.Contains(logger.LevelInfo, "synchronous") {
+		t.Fatal("expected synchronous dispatch after Close")
+	}
+}
+
+func TestAdminHandlerChangesLevel(t *testing.T) {
+	log := logger.Namespace("Admin-Test")
+	admin := logger.AdminHandler()
+
+	req := httptest.NewRequest("POST", "/logger", strings.NewReader(`{"namespace":"admin-test","level":"debug"}`))
+	w := httptest.NewRecorder()
+	admin.ServeHTTP(w, req)
+	if w.Code != 200 || log.GetLevel() != logger.LevelDebug {
+		t.Fatal("expected the level to change, got", w.Code, log.GetLevel())
+	}
+
+	req = httptest.NewRequest("PUT", "/logger/admin-test", strings.NewReader(`{"level":"loud"}`))
+	w = httptest.NewRecorder()
+	admin.ServeHTTP(w, req)
+	if w.Code != 400 || log.GetLevel() != logger.LevelDebug {
+		t.Fatal("expected an unknown level to be rejected, got", w.Code, log.GetLevel())
+	}
+
+	req = httptest.NewRequest("PUT", "/logger/admin-test", strings.NewReader(`{"level":`))
+	w = httptest.NewRecorder()
+	admin.ServeHTTP(w, req)
+	if w.Code != 400 {
+		t.Fatal("expected malformed json to be rejected, got", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/logger/admin-test", nil)
+	w = httptest.NewRecorder()
+	admin.ServeHTTP(w, req)
+	if w.Body.String() != `{"level":"debug","namespace":"Admin-Test"}` {
+		t.Fatal("unexpected namespace", w.Body.String())
+	}
+}
+
+func TestNamespaceHierarchyInheritsAndCascades(t *testing.T) {
+	defer os.Unsetenv("SEVERINO_LOGGER_TREE_DB_CACHE")
+	os.Setenv("SEVERINO_LOGGER_TREE_DB_CACHE", "error")
+
+	var buf safeBuffer
+	app := logger.NamespaceWithWriter("tree", &buf, logger.LevelWarn)
+	db := logger.Namespace("tree.db")
+	pool := logger.Namespace("tree.db.pool")
+	cache := logger.Namespace("tree.db.cache")
+
+	if db.GetLevel() != logger.LevelWarn || pool.GetLevel() != logger.LevelWarn || cache.GetLevel() != logger.LevelError {
+		t.Fatal("expected the level of the nearest configured ancestor, got", db.GetLevel(), pool.GetLevel(), cache.GetLevel())
+	}
+
+	pool.Warn("slow")
+	if buf.String() != "<tree.db.pool> [WARN] slow\n" {
+		t.Fatalf("expected the handlers of the ancestor, got %q", buf.String())
+	}
+
+	app.SetLevel(logger.LevelDebug)
+	if db.GetLevel() != logger.LevelDebug || pool.GetLevel() != logger.LevelDebug || cache.GetLevel() != logger.LevelError {
+		t.Fatal("expected SetLevel to cascade, got", db.GetLevel(), pool.GetLevel(), cache.GetLevel())
+	}
+
+	db.SetLevel(logger.LevelInfo)
+	app.SetLevel(logger.LevelError)
+	if db.GetLevel() != logger.LevelInfo || pool.GetLevel() != logger.LevelInfo {
+		t.Fatal("expected the nearer ancestor to win, got", db.GetLevel(), pool.GetLevel())
+	}
+}
+
+type initCounter struct {
+	logger.MemoryHandler
+	inits int32
+}
+
+func (handler *initCounter) Init(namespace string, level logger.Level) {
+	atomic.AddInt32(&handler.inits, 1)
+	handler.MemoryHandler.Init(namespace, level)
+}
+
+func TestNamespaceSharesAncestorHandlersWithoutInit(t *testing.T) {
+	handler := &initCounter{}
+	parent := logger.Namespace("shared")
+	parent.SetHandlers([]logger.Interface{handler})
+	child := logger.Namespace("shared.child")
+
+	parent.Info("from parent")
+	child.Info("from child")
+	if inits := atomic.LoadInt32(&handler.inits); inits != 1 {
+		t.Fatal("expected the inherited handler to be initialized once, got", inits)
+	}
+	entries := handler.Entries()
+	if len(entries) != 2 || entries[0].Namespace != "shared" || entries[1].Namespace != "shared.child" {
+		t.Fatal("unexpected entries", entries)
+	}
+	if child.Close() != nil || !child.RemoveHandler(handler) {
+		t.Fatal("expected the inherited handler to be found")
+	}
+}
+
+type spanKey struct{}
+
+func TestTraceExtractorAttachesIDs(t *testing.T) {
+	logger.RegisterTraceExtractor(func(ctx context.Context) (string, string) {
+		if span, ok := ctx.Value(spanKey{}).([2]string); ok {
+			return span[0], span[1]
+		}
+		return "", ""
+	})
+
+	memory := logger.NewMemoryHandler()
+	log := logger.NewLogger("", logger.LevelInfo, memory)
+
+	log.InfoCtx(context.WithValue(context.Background(), spanKey{}, [2]string{"4bf92f35", "00f067aa"}), "traced")
+	log.InfoCtx(context.Background(), "untraced")
+
+	entries := memory.Entries()
+	if entries[0].Fields[logger.TraceIDField] != "4bf92f35" || entries[0].Fields[logger.SpanIDField] != "00f067aa" {
+		t.Fatal("expected the trace IDs, got", entries[0].Fields)
+	}
+	if len(entries[1].Fields) != 0 {
+		t.Fatal("expected no fields without a span, got", entries[1].Fields)
+	}
+}
+
+func TestErrorErrAttachesErrorChain(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	log := logger.NewLogger("", logger.LevelInfo, memory)
+
+	root := errors.New("connection refused")
+	err := fmt.Errorf("query users: %w", root)
+
+	logger.ErrorStacks = true
+	defer func() { logger.ErrorStacks = false }()
+	log.ErrorErr(err, "request %d failed", 7)
+
+	entries := memory.Entries()
+	fields := entries[0].Fields
+	if entries[0].Msg != "request 7 failed" || fields[logger.ErrorField] != "query users: connection refused" {
+		t.Fatalf("unexpected entry %+v", entries[0])
+	}
+	if chain, ok := fields[logger.ErrorChainField].([]string); !ok || len(chain) != 2 || chain[1] != "connection refused" {
+		t.Fatal("unexpected chain", fields[logger.ErrorChainField])
+	}
+	if stack, _ := fields[logger.StackField].(string); !strings.HasPrefix(stack, "github.com/NeowayLabs/logger_test.TestErrorErrAttachesErrorChain ") {
+		t.Fatal("expected the stack to start at the call site, got", stack)
+	}
+
+	if log.WithError(nil) != log {
+		t.Fatal("expected a nil error to return the logger itself")
+	}
+}
+
+type selfWrapping struct{}
+
+func (selfWrapping) Error() string     { return "self" }
+func (err selfWrapping) Unwrap() error { return err }
+
+func TestErrorChainIsBounded(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	log := logger.NewLogger("", logger.LevelInfo, memory)
+
+	log.ErrorErr(selfWrapping{}, "cycle")
+	if chain, _ := memory.Entries()[0].Fields[logger.ErrorChainField].([]string); len(chain) != 32 {
+		t.Fatal("expected the chain to be cut at 32 errors, got", len(chain))
+	}
+}
+
+func TestSetSamplingCountsDropped(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	log := logger.NewLogger("", logger.LevelInfo, memory)
+	log.SetSampling(logger.Sampling{Initial: 5, Thereafter: 10, Tick: time.Hour})
+
+	for i := 0; i < 105; i++ {
+		log.Info("loop %d", i)
+	}
+	log.Info("other")
+
+	if kept := len(memory.Entries()); kept != 16 || log.Dropped() != 90 {
+		t.Fatal("expected 5 + 1 of every 10 repeats and the other message, got", kept, log.Dropped())
+	}
+	if log.CollectMetrics().Dropped != 90 {
+		t.Fatal("expected the dropped messages in the metrics, got", log.CollectMetrics())
+	}
+
+	log.SetSampling(logger.Sampling{})
+	log.Info("loop %d", 0)
+	if log.Dropped() != 90 {
+		t.Fatal("expected sampling to be off")
+	}
+}
+
+func TestStdLoggerLogsAtLevel(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	log := logger.NewLogger("", logger.LevelWarn, memory)
+
+	log.StdLogger(logger.LevelError).Printf("accept: %s", "too many open files")
+	fmt.Fprintln(log.Writer(logger.LevelDebug), "discarded")
+	fmt.Fprintln(log.Writer(logger.LevelWarn), "slow query")
+	log.StdLogger(logger.Level(9)).Print("clamped to debug")
+
+	entries := memory.Entries()
+	if len(entries) != 2 || !memory.Contains(logger.LevelError, "accept: too many open files") ||
+		!memory.Contains(logger.LevelWarn, "slow query") {
+		t.Fatal("unexpected entries", fmt.Sprint(entries))
+	}
+}
+
+func TestHooksChangeAndDropRecords(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	log := (logger.NewLogger("", logger.LevelInfo, memory)).With("app", "api")
+	log.AddHook(func(record *logger.Record) *logger.Record {
+		if strings.Contains(record.Message, "/healthz") {
+			return nil
+		}
+		if _, ok := record.Fields["host"]; ok {
+			t.Error("expected hooks to get a copy of the fields of the logger")
+		}
+		record.Message = strings.Replace(record.Message, "hunter2", "***", -1)
+		record.Fields["host"] = "web-1"
+		return record
+	})
+
+	log.Info("GET /healthz")
+	log.Info("login password=hunter2")
+	log.Info("done")
+
+	entries := memory.Entries()
+	if len(entries) != 2 || entries[0].Msg != "login password=***" || entries[1].Fields["host"] != "web-1" {
+		t.Fatal("unexpected entries", fmt.Sprint(entries))
+	}
+}
+
+type recordCapture struct {
+	records []logger.Record
+}
+
+func (capture *recordCapture) Log(record logger.Record) {
+	capture.records = append(capture.records, record)
+}
+
+func TestSetReportCallerFillsRecord(t *testing.T) {
+	capture := &recordCapture{}
+	log := logger.NewLogger("", logger.LevelInfo, capture)
+
+	log.Info("without caller")
+	log.SetReportCaller(true)
+	_, file, line, _ := runtime.Caller(0)
+	log.Info("with caller")
+	log.SetReportCaller(false)
+	log.Info("without caller again")
+
+	if len(capture.records) != 3 || capture.records[0].Caller != "" || capture.records[2].Caller != "" {
+		t.Fatal("expected the caller only while enabled, got", capture.records)
+	}
+	record := capture.records[1]
+	if record.File != file || record.Line != line+1 || !strings.HasSuffix(record.Function, ".TestSetReportCallerFillsRecord") {
+		t.Fatalf("unexpected call site %s:%d %s", record.File, record.Line, record.Function)
+	}
+}
+
+func TestStatsCountEmittedAndSuppressed(t *testing.T) {
+	log := logger.Namespace("stats-test")
+	log.SetHandlers([]logger.Interface{logger.NewMemoryHandler()})
+	log.SetLevel(logger.LevelWarn)
+	log.Debug("hidden")
+	log.Info("hidden")
+	log.Info("hidden")
+	log.Error("shown")
+	log.With("derived", true).Error("shown")
+	log.WithError(errors.New("refused")).WithCaller().Error("shown")
+
+	for _, stats := range logger.Stats() {
+		if stats.Namespace != "stats-test" {
+			continue
+		}
+		if stats.Level != logger.LevelWarn || stats.Emitted[logger.LevelError] != 3 ||
+			stats.Suppressed[logger.LevelInfo] != 2 || stats.Suppressed[logger.LevelDebug] != 1 {
+			t.Fatalf("unexpected stats %+v", stats)
+		}
+		if _, err := json.Marshal(stats); err != nil {
+			t.Fatal("expected the stats to encode as JSON", err)
+		}
+		return
+	}
+	t.Fatal("expected stats for the namespace")
+}
+
+func TestLevelAndHandlersChangeWhileLogging(t *testing.T) {
+	log := logger.Namespace("race-stress")
+	stock := log.Handlers()[0].(*logger.DefaultHandler)
+	for _, level := range []logger.Level{logger.LevelDebug, logger.LevelInfo, logger.LevelWarn, logger.LevelError} {
+		stock.SetOutput(level, io.Discard)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				log.Info("message %d", j)
+				log.With("j", j).Warn("derived %d", j)
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		added := &logger.DefaultHandler{Output: io.Discard}
+		log.AddHandler(added)
+		log.SetLevel(logger.Level(i%2) + logger.LevelInfo)
+		log.RemoveHandler(added)
+	}
+	close(done)
+	wg.Wait()
+
+	if handlers := log.Handlers(); len(handlers) != 1 || handlers[0] != stock {
+		t.Fatal("expected only the stock handler to be left, got", handlers)
+	}
+}
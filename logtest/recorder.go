@@ -0,0 +1,141 @@
+// Package logtest captures what the code under test logs, through every namespace, and puts the registry of
+// namespaces back when the test ends.
+package logtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NeowayLabs/logger"
+)
+
+// Recorder it's a logger.MemoryHandler with assertion helpers
+type Recorder struct {
+	*logger.MemoryHandler
+}
+
+// NewRecorder return a recorder to add to a logger by hand, see Install to capture every namespace
+func NewRecorder() *Recorder {
+	return &Recorder{MemoryHandler: logger.NewMemoryHandler()}
+}
+
+// Install make a new recorder the only handler of every namespace, including the ones created during the test, until
+// the test ends. Then the handlers and levels are restored and the namespaces created by the test are forgotten. Tests
+// calling Install can't run in parallel
+func Install(t testing.TB) *Recorder {
+	recorder := NewRecorder()
+	t.Cleanup(logger.SaveNamespaces())
+
+	for _, log := range logger.Loggers() {
+		log.SetHandlers([]logger.Interface{recorder})
+	}
+	logger.SetNamespaceHandlerResolver(func(namespace string) []logger.Interface {
+		return []logger.Interface{recorder}
+	})
+
+	return recorder
+}
+
+// HasEntry tell whether any entry of level has msg in its message
+func (recorder *Recorder) HasEntry(level logger.Level, msg string) bool {
+	return recorder.Contains(level, msg)
+}
+
+// HasField tell whether any entry has the field key with value
+func (recorder *Recorder) HasField(key string, value interface{}) bool {
+	for _, entry := range recorder.Entries() {
+		if fieldValue, ok := entry.Fields[key]; ok && reflect.DeepEqual(fieldValue, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Messages return the messages of level, oldest first
+func (recorder *Recorder) Messages(level logger.Level) []string {
+	var messages []string
+	for _, entry := range recorder.Entries() {
+		if entry.Level == level {
+			messages = append(messages, entry.Msg)
+		}
+	}
+
+	return messages
+}
+
+// AssertEntry fail the test, listing what was logged, when no entry of level has msg in its message
+func (recorder *Recorder) AssertEntry(t testing.TB, level logger.Level, msg string) {
+	t.Helper()
+
+	if !recorder.HasEntry(level, msg) {
+		t.Errorf("expected a %s entry with %q, got %s", level, msg, recorder.dump())
+	}
+}
+
+// AssertNoEntry fail the test when any entry of level was logged
+func (recorder *Recorder) AssertNoEntry(t testing.TB, level logger.Level) {
+	t.Helper()
+
+	if messages := recorder.Messages(level); len(messages) > 0 {
+		t.Errorf("expected no %s entry, got %q", level, messages)
+	}
+}
+
+// CountAll it's the number of entries of every level
+func (recorder *Recorder) CountAll() int {
+	return len(recorder.Entries())
+}
+
+// Count it's the number of entries of level
+func (recorder *Recorder) Count(level logger.Level) int {
+	return len(recorder.Messages(level))
+}
+
+// AssertCount fail the test, listing what was logged, unless exactly n entries of level were logged, e.g. to catch
+// messages logged twice
+func (recorder *Recorder) AssertCount(t testing.TB, level logger.Level, n int) {
+	t.Helper()
+
+	if count := recorder.Count(level); count != n {
+		t.Errorf("expected %d %s entries, got %d: %s", n, level, count, recorder.dump())
+	}
+}
+
+// AssertOrder fail the test, showing the expected and the actual order, unless the messages contain each of substrs in
+// this relative order, other messages may come in between
+func (recorder *Recorder) AssertOrder(t testing.TB, substrs ...string) {
+	t.Helper()
+
+	var messages []string
+	for _, entry := range recorder.Entries() {
+		messages = append(messages, entry.Msg)
+	}
+
+	next := 0
+	for _, msg := range messages {
+		if next < len(substrs) && strings.Contains(msg, substrs[next]) {
+			next++
+		}
+	}
+	if next < len(substrs) {
+		t.Errorf("expected the messages in the order %q, %q was not found after %q, got %s", substrs, substrs[next],
+			substrs[:next], recorder.dump())
+	}
+}
+
+func (recorder *Recorder) dump() string {
+	entries := recorder.Entries()
+	if len(entries) == 0 {
+		return "nothing"
+	}
+
+	var dump string
+	for _, entry := range entries {
+		dump += fmt.Sprintf("\n\t%s <%s> %s %v", entry.Level, entry.Namespace, entry.Msg, entry.Fields)
+	}
+
+	return dump
+}
@@ -0,0 +1,87 @@
+package logtest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/NeowayLabs/logger"
+	"github.com/NeowayLabs/logger/logtest"
+)
+
+func TestInstallCapturesEveryNamespaceAndRestores(t *testing.T) {
+	existing := logger.Namespace("logtest-existing")
+	handlers := existing.Handlers()
+
+	t.Run("captured", func(t *testing.T) {
+		recorder := logtest.Install(t)
+
+		existing.Error("connection refused")
+		logger.Namespace("logtest-new").With("attempt", 3).Warn("retrying")
+		logger.Info("started")
+
+		recorder.AssertEntry(t, logger.LevelError, "connection refused")
+		recorder.AssertEntry(t, logger.LevelInfo, "started")
+		recorder.AssertNoEntry(t, logger.LevelDebug)
+		if !recorder.HasField("attempt", 3) || len(recorder.Messages(logger.LevelWarn)) != 1 {
+			t.Fatal("expected the entry of the new namespace, got", recorder.Entries())
+		}
+	})
+
+	if current := existing.Handlers(); len(current) != len(handlers) || current[0] != handlers[0] {
+		t.Fatal("expected the handlers to be restored, got", current)
+	}
+	for _, log := range logger.Loggers() {
+		if log.Namespace == "logtest-new" {
+			t.Fatal("expected the namespace created by the test to be forgotten")
+		}
+	}
+}
+
+// failures records the failures of an assertion instead of failing the test
+type failures struct {
+	testing.TB
+	errors []string
+}
+
+func (t *failures) Helper() {}
+
+func (t *failures) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertOrder(t *testing.T) {
+	recorder := logtest.NewRecorder()
+	log := logger.NewLogger("order", logger.LevelInfo, recorder)
+	log.Info("connecting to db")
+	log.Warn("retrying")
+	log.Info("connected")
+
+	recorder.AssertOrder(t, "connecting", "connected")
+
+	failed := &failures{TB: t}
+	recorder.AssertOrder(failed, "connected", "retrying")
+	if len(failed.errors) != 1 || !strings.Contains(failed.errors[0], `"retrying" was not found after ["connected"]`) {
+		t.Fatal("expected the order to be reported, got", failed.errors)
+	}
+}
+
+func TestCountAndAssertCount(t *testing.T) {
+	recorder := logtest.NewRecorder()
+	log := logger.NewLogger("count", logger.LevelInfo, recorder)
+	log.Info("saved")
+	log.Info("saved")
+	log.Error("failed")
+
+	if recorder.CountAll() != 3 || recorder.Count(logger.LevelInfo) != 2 || recorder.Count(logger.LevelWarn) != 0 {
+		t.Fatal("unexpected counts", recorder.CountAll(), recorder.Count(logger.LevelInfo))
+	}
+	recorder.AssertCount(t, logger.LevelError, 1)
+
+	failed := &failures{TB: t}
+	recorder.AssertCount(failed, logger.LevelInfo, 1)
+	if len(failed.errors) != 1 || !strings.Contains(failed.errors[0], "expected 1 info entries, got 2") ||
+		strings.Count(failed.errors[0], "<count> saved") != 2 {
+		t.Fatal("expected the entries to be listed, got", failed.errors)
+	}
+}
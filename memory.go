@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Entry it's a message kept by MemoryHandler
+	Entry struct {
+		Level     Level
+		Namespace string
+		Msg       string
+		Time      time.Time
+		Fields    map[string]interface{}
+		// Fatal it's true for messages of Fatal and Panic, their Level is LevelFatal
+		Fatal bool
+	}
+
+	// MemoryHandler keeps every message it receives, for assertions in tests
+	MemoryHandler struct {
+		// Limit when greater than 0 keeps only the last Limit entries
+		Limit int
+
+		lock      sync.Mutex
+		entries   []Entry
+		namespace string
+	}
+)
+
+// NewMemoryHandler ...
+func NewMemoryHandler() *MemoryHandler {
+	return &MemoryHandler{}
+}
+
+func (handler *MemoryHandler) Init(namespace string, level Level) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	handler.namespace = namespace
+}
+
+func (handler *MemoryHandler) Log(record Record) {
+	handler.add(Entry{
+		Level:     record.Level,
+		Namespace: record.Namespace,
+		Msg:       record.Message,
+		Time:      record.Time,
+		Fields:    record.Fields,
+	})
+}
+
+func (handler *MemoryHandler) Debug(msg string) {
+	handler.add(Entry{Level: LevelDebug, Msg: msg})
+}
+
+func (handler *MemoryHandler) Info(msg string) {
+	handler.add(Entry{Level: LevelInfo, Msg: msg})
+}
+
+func (handler *MemoryHandler) Warn(msg string) {
+	handler.add(Entry{Level: LevelWarn, Msg: msg})
+}
+
+func (handler *MemoryHandler) Error(msg string) {
+	handler.add(Entry{Level: LevelError, Msg: msg})
+}
+
+func (handler *MemoryHandler) Fatal(msg string) {
+	handler.add(Entry{Level: LevelFatal, Msg: msg, Fatal: true})
+}
+
+func (handler *MemoryHandler) FatalFields(msg string, fields map[string]interface{}) {
+	handler.add(Entry{Level: LevelFatal, Msg: msg, Fields: fields, Fatal: true})
+}
+
+// Entries return a copy of the entries kept, oldest first
+func (handler *MemoryHandler) Entries() []Entry {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	entries := make([]Entry, len(handler.entries))
+	copy(entries, handler.entries)
+
+	return entries
+}
+
+// Contains tell whether any entry of level has substr in its message
+func (handler *MemoryHandler) Contains(level Level, substr string) bool {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	for _, entry := range handler.entries {
+		if entry.Level == level && strings.Contains(entry.Msg, substr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reset drop every entry kept
+func (handler *MemoryHandler) Reset() {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	handler.entries = nil
+}
+
+// add fills the namespace and time of the level methods, that don't get a record
+func (handler *MemoryHandler) add(entry Entry) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Namespace = handler.namespace
+		entry.Time = now()
+	}
+
+	handler.entries = append(handler.entries, entry)
+	if handler.Limit > 0 && len(handler.entries) > handler.Limit {
+		handler.entries = append(handler.entries[:0], handler.entries[len(handler.entries)-handler.Limit:]...)
+	}
+}
@@ -0,0 +1,54 @@
+package logger_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/NeowayLabs/logger"
+)
+
+func TestMemoryHandlerCapturesEntries(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	log := logger.NewLogger("memory", logger.LevelInfo)
+	log.AddHandler(memory)
+
+	log.With("user", 42).Info("user %d signed in", 42)
+	log.Debug("not captured")
+	log.Error("payment failed")
+
+	entries := memory.Entries()
+	if len(entries) != 2 || entries[0].Namespace != "memory" || entries[0].Fields["user"] != 42 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if !memory.Contains(logger.LevelError, "payment") || memory.Contains(logger.LevelInfo, "payment") {
+		t.Fatal("unexpected Contains result")
+	}
+
+	memory.Reset()
+	if len(memory.Entries()) != 0 {
+		t.Fatal("expected no entries after Reset")
+	}
+}
+
+func TestMemoryHandlerLimit(t *testing.T) {
+	memory := logger.NewMemoryHandler()
+	memory.Limit = 10
+	log := logger.NewLogger("", logger.LevelInfo, memory)
+
+	var wait sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			log.Info("message %d", i)
+		}(i)
+	}
+	wait.Wait()
+	log.Info("last")
+
+	entries := memory.Entries()
+	if len(entries) != 10 || entries[9].Msg != "last" {
+		t.Fatal("expected the last 10 entries, got", fmt.Sprint(entries))
+	}
+}
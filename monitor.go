@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// rateMonitorBuckets it's how many slices the window of a rate monitor is split into
+const rateMonitorBuckets = 10
+
+// rateMonitor it's a sliding window counter of the messages of a level
+type rateMonitor struct {
+	level     Level
+	threshold int
+	width     time.Duration
+	window    time.Duration
+	callback  func(count int)
+
+	lock      sync.Mutex
+	slots     [rateMonitorBuckets]int64
+	counts    [rateMonitorBuckets]int
+	lastFired time.Time
+}
+
+// OnRateExceeded call cb with the count of messages when more than threshold messages of level were logged within the
+// last window. The count is kept in slices of a tenth of the window, and cb is called at most once per window, from
+// the goroutine that logged the message that crossed the threshold
+func (logger *Logger) OnRateExceeded(level Level, threshold int, window time.Duration, cb func(count int)) {
+	monitor := &rateMonitor{
+		level:     level,
+		threshold: threshold,
+		width:     window / rateMonitorBuckets,
+		window:    window,
+		callback:  cb,
+	}
+	if monitor.width <= 0 {
+		monitor.width = 1
+	}
+
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	monitors := make([]*rateMonitor, len(logger.monitors), len(logger.monitors)+1)
+	copy(monitors, logger.monitors)
+	logger.monitors = append(monitors, monitor)
+}
+
+func (logger *Logger) observe(level Level) {
+	logger.handlersLock.Lock()
+	monitors := logger.monitors
+	logger.handlersLock.Unlock()
+
+	for _, monitor := range monitors {
+		if monitor.level == level {
+			monitor.observe(time.Now())
+		}
+	}
+}
+
+func (monitor *rateMonitor) observe(t time.Time) {
+	monitor.lock.Lock()
+
+	slot := t.UnixNano() / int64(monitor.width)
+	i := slot % rateMonitorBuckets
+	if monitor.slots[i] != slot {
+		monitor.slots[i], monitor.counts[i] = slot, 0
+	}
+	monitor.counts[i]++
+
+	count := 0
+	for j := range monitor.counts {
+		if slot-monitor.slots[j] < rateMonitorBuckets {
+			count += monitor.counts[j]
+		}
+	}
+
+	fire := count > monitor.threshold && t.Sub(monitor.lastFired) >= monitor.window
+	if fire {
+		monitor.lastFired = t
+	}
+	monitor.lock.Unlock()
+
+	if fire {
+		monitor.callback(count)
+	}
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NeowayLabs/logger/internal/batch"
+)
+
+const (
+	defaultNetworkQueueSize     = 1024
+	defaultNetworkBatchSize     = 100
+	defaultNetworkFlushInterval = time.Second
+	networkMaxRetryBackoff      = 30 * time.Second
+	networkTimeout              = 10 * time.Second
+)
+
+// NetworkHandler ships every record as a JSON line to a remote endpoint, in batches, from a background goroutine. The
+// address is either a tcp "host:port" or an http(s) URL that gets each batch as the body of a POST, like the push
+// endpoints of Logstash. While the endpoint is unavailable the batch is retried with exponential backoff and records
+// are kept in a bounded queue, when the queue is full new records are dropped and counted. Call Close before the
+// program exits to send what is left
+type NetworkHandler struct {
+	address string
+	client  *http.Client
+
+	namespace atomic.Value
+	batcher   *batch.Batcher
+	closeOnce sync.Once
+	// conn it's only used by the goroutine of the batcher, and closed after it's done
+	conn net.Conn
+}
+
+// NewNetworkHandler start a handler shipping to address, queueSize bounds the records waiting to be shipped, 0 means
+// the default of 1024
+func NewNetworkHandler(address string, queueSize int) *NetworkHandler {
+	if queueSize <= 0 {
+		queueSize = defaultNetworkQueueSize
+	}
+
+	handler := &NetworkHandler{
+		address: address,
+		client:  &http.Client{Timeout: networkTimeout},
+	}
+	handler.namespace.Store("")
+	handler.batcher = batch.New(handler.send, queueSize, defaultNetworkBatchSize, defaultNetworkFlushInterval,
+		networkMaxRetryBackoff)
+
+	return handler
+}
+
+func (handler *NetworkHandler) Init(namespace string, level Level) {
+	handler.namespace.Store(namespace)
+}
+
+// Log ships the record with its own namespace and time
+func (handler *NetworkHandler) Log(record Record) {
+	handler.enqueue(levelToString(record.Level), record)
+}
+
+func (handler *NetworkHandler) Debug(msg string) {
+	handler.enqueue("debug", handler.record(LevelDebug, msg, nil))
+}
+
+func (handler *NetworkHandler) Info(msg string) {
+	handler.enqueue("info", handler.record(LevelInfo, msg, nil))
+}
+
+func (handler *NetworkHandler) Warn(msg string) {
+	handler.enqueue("warn", handler.record(LevelWarn, msg, nil))
+}
+
+func (handler *NetworkHandler) Error(msg string) {
+	handler.enqueue("error", handler.record(LevelError, msg, nil))
+}
+
+// Fatal ships the message with what is queued right away, the handler stays open because Panic can be recovered
+func (handler *NetworkHandler) Fatal(msg string) {
+	handler.FatalFields(msg, nil)
+}
+
+func (handler *NetworkHandler) FatalFields(msg string, fields map[string]interface{}) {
+	handler.enqueue("fatal", handler.record(LevelFatal, msg, fields))
+	handler.Flush()
+}
+
+// Flush make an attempt to ship the queued records and return when it's done
+func (handler *NetworkHandler) Flush() {
+	handler.batcher.Flush()
+}
+
+// Metrics ...
+func (handler *NetworkHandler) Metrics() HandlerMetrics {
+	return HandlerMetrics{
+		Emitted: handler.batcher.Sent(),
+		Dropped: handler.batcher.Dropped(),
+		Errors:  handler.batcher.Failed(),
+	}
+}
+
+// Close stop accepting records and make a last attempt to ship the queued ones
+func (handler *NetworkHandler) Close() error {
+	handler.batcher.Close()
+	handler.closeOnce.Do(func() {
+		if handler.conn != nil {
+			handler.conn.Close()
+		}
+	})
+
+	return nil
+}
+
+func (handler *NetworkHandler) record(level Level, msg string, fields map[string]interface{}) Record {
+	return Record{Level: level, Namespace: handler.namespace.Load().(string), Message: msg, Time: now(), Fields: fields}
+}
+
+func (handler *NetworkHandler) enqueue(level string, record Record) {
+	if handler.batcher.Closed() {
+		handler.batcher.Drop()
+		return
+	}
+
+	line, err := encodeJSON(level, record)
+	if err != nil {
+		handler.batcher.Drop()
+		return
+	}
+
+	handler.batcher.Add(append(line, '\n'))
+}
+
+func (handler *NetworkHandler) send(lines []interface{}) error {
+	var body []byte
+	for _, line := range lines {
+		body = append(body, line.([]byte)...)
+	}
+
+	if strings.HasPrefix(handler.address, "http://") || strings.HasPrefix(handler.address, "https://") {
+		response, err := handler.client.Post(handler.address, "application/x-ndjson", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return fmt.Errorf("logger: %s answered %s", handler.address, response.Status)
+		}
+		return nil
+	}
+
+	if handler.conn == nil {
+		conn, err := net.DialTimeout("tcp", handler.address, networkTimeout)
+		if err != nil {
+			return err
+		}
+		handler.conn = conn
+	}
+
+	handler.conn.SetWriteDeadline(time.Now().Add(networkTimeout))
+	if _, err := handler.conn.Write(body); err != nil {
+		// the batch may have been partially written, it's sent again whole on the next connection
+		handler.conn.Close()
+		handler.conn = nil
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNetworkHandlerPostsBatchesOnClose(t *testing.T) {
+	var lock sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+
+		if r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Error("unexpected content type", r.Header.Get("Content-Type"))
+		}
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	TestMode = true
+	defer func() { TestMode = false }()
+
+	handler := NewNetworkHandler(server.URL, 0)
+	handler.Init("ship", LevelInfo)
+	handler.Info("first")
+	handler.Log(Record{Level: LevelWarn, Namespace: "other", Message: "second", Time: now()})
+	handler.Close()
+	handler.Info("after close")
+
+	lock.Lock()
+	defer lock.Unlock()
+	expected := `{"level":"info","msg":"first","namespace":"ship","time":"2000-01-01T00:00:00Z"}` + "\n" +
+		`{"level":"warn","msg":"second","namespace":"other","time":"2000-01-01T00:00:00Z"}` + "\n"
+	if strings.Join(bodies, "") != expected {
+		t.Fatalf("expected %q, got %q", expected, bodies)
+	}
+	if metrics := handler.Metrics(); metrics.Emitted != 2 || metrics.Dropped != 1 {
+		t.Fatal("unexpected metrics", metrics)
+	}
+}
+
+func TestNetworkHandlerKeepsShippingAfterRecoveredPanic(t *testing.T) {
+	var lock sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	handler := NewNetworkHandler(server.URL, 0)
+	log := NewLogger("panic-ship", LevelInfo, handler)
+
+	func() {
+		defer func() { recover() }()
+		log.Panic("recoverable")
+	}()
+	log.Info("after panic")
+	handler.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	shipped := strings.Join(bodies, "")
+	if !strings.Contains(shipped, `"msg":"recoverable"`) || !strings.Contains(shipped, `"msg":"after panic"`) {
+		t.Fatalf("expected both records to be shipped, got %q", shipped)
+	}
+	if metrics := handler.Metrics(); metrics.Emitted != 2 || metrics.Dropped != 0 {
+		t.Fatal("unexpected metrics", metrics)
+	}
+}
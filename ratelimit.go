@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorsBypassRateLimit let Error messages through even when the rate limit of the namespace is exhausted
+var ErrorsBypassRateLimit = true
+
+// rateLimiter it's a token bucket that holds up to limit tokens and refills limit tokens every period
+type rateLimiter struct {
+	lock   sync.Mutex
+	limit  float64
+	period time.Duration
+	tokens float64
+	last   time.Time
+}
+
+// parseRate parse rates like "100/s", "600/m" or "1000/h"
+func parseRate(rate string) (*rateLimiter, error) {
+	parts := strings.SplitN(strings.TrimSpace(rate), "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid rate '%s', expected <count>/<s|m|h>", rate)
+	}
+
+	limit, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || limit == 0 {
+		return nil, fmt.Errorf("invalid rate '%s', count must be a positive integer", rate)
+	}
+
+	var period time.Duration
+	switch strings.ToLower(parts[1]) {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		return nil, fmt.Errorf("invalid rate '%s', unit must be s, m or h", rate)
+	}
+
+	return &rateLimiter{
+		limit:  float64(limit),
+		period: period,
+		tokens: float64(limit),
+		last:   time.Now(),
+	}, nil
+}
+
+func (limiter *rateLimiter) allow() bool {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	current := time.Now()
+	limiter.tokens += float64(current.Sub(limiter.last)) / float64(limiter.period) * limiter.limit
+	if limiter.tokens > limiter.limit {
+		limiter.tokens = limiter.limit
+	}
+	limiter.last = current
+
+	if limiter.tokens < 1 {
+		return false
+	}
+	limiter.tokens--
+
+	return true
+}
+
+// getEnvVarRateName it's the level variable of namespace with a double underscore before RATE, so it can't be taken for
+// the level variable of a namespace ending in ".rate"
+func getEnvVarRateName(namespace string) string {
+	return getEnvVarName(namespace) + "__RATE"
+}
+
+func getEnvVarRate(namespace string) *rateLimiter {
+	rate := os.Getenv(getEnvVarRateName(namespace))
+	if rate == "" {
+		return nil
+	}
+
+	limiter, err := parseRate(rate)
+	if err != nil {
+		if StrictEnvLevels {
+			panic(fmt.Sprintf("logger: %s for namespace '%s'", err, namespace))
+		}
+		return nil
+	}
+
+	return limiter
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the values of the redacted fields and the parts of the messages matching a redaction pattern
+const Redacted = "[REDACTED]"
+
+var redactedFields map[string]bool
+var redactPatterns []*regexp.Regexp
+var redactLock sync.Mutex
+
+// RedactFields mask the values of the fields with these names, case insensitive, in the messages of every logger
+func RedactFields(names ...string) {
+	redactLock.Lock()
+	defer redactLock.Unlock()
+
+	fields := make(map[string]bool, len(redactedFields)+len(names))
+	for name := range redactedFields {
+		fields[name] = true
+	}
+	for _, name := range names {
+		fields[strings.ToLower(name)] = true
+	}
+	redactedFields = fields
+}
+
+// RedactPattern mask the matches of pattern in the messages of every logger and in their string, error and
+// fmt.Stringer field values, including the ones inside slices and maps
+func RedactPattern(pattern *regexp.Regexp) {
+	redactLock.Lock()
+	defer redactLock.Unlock()
+
+	patterns := make([]*regexp.Regexp, len(redactPatterns), len(redactPatterns)+1)
+	copy(patterns, redactPatterns)
+	redactPatterns = append(patterns, pattern)
+}
+
+func redacting() bool {
+	redactLock.Lock()
+	defer redactLock.Unlock()
+
+	return len(redactedFields) > 0 || len(redactPatterns) > 0
+}
+
+// redact mask record in place, its fields must be a copy
+func redact(record *Record) {
+	redactLock.Lock()
+	fields, patterns := redactedFields, redactPatterns
+	redactLock.Unlock()
+
+	record.Message = redactString(record.Message, patterns)
+	for key, value := range record.Fields {
+		if masked, ok := redactField(key, value, fields, patterns); ok {
+			record.Fields[key] = masked
+		}
+	}
+}
+
+// redactField return the masked value of the field and whether it changed, slices and maps are masked recursively into
+// copies, because they may be shared with the fields of the logger
+func redactField(key string, value interface{}, fields map[string]bool, patterns []*regexp.Regexp) (interface{}, bool) {
+	if fields[strings.ToLower(key)] {
+		return Redacted, true
+	}
+
+	switch value := value.(type) {
+	case string:
+		masked := redactString(value, patterns)
+		return masked, masked != value
+	case error, fmt.Stringer:
+		text := fmt.Sprint(value)
+		masked := redactString(text, patterns)
+		return masked, masked != text
+	case []string:
+		var copied []string
+		for i, text := range value {
+			if masked := redactString(text, patterns); masked != text {
+				if copied == nil {
+					copied = append([]string(nil), value...)
+				}
+				copied[i] = masked
+			}
+		}
+		return copied, copied != nil
+	case []interface{}:
+		var copied []interface{}
+		for i, inner := range value {
+			if masked, ok := redactField("", inner, fields, patterns); ok {
+				if copied == nil {
+					copied = append([]interface{}(nil), value...)
+				}
+				copied[i] = masked
+			}
+		}
+		return copied, copied != nil
+	case map[string]interface{}:
+		var copied map[string]interface{}
+		for innerKey, inner := range value {
+			if masked, ok := redactField(innerKey, inner, fields, patterns); ok {
+				if copied == nil {
+					copied = make(map[string]interface{}, len(value))
+					for k, v := range value {
+						copied[k] = v
+					}
+				}
+				copied[innerKey] = masked
+			}
+		}
+		return copied, copied != nil
+	}
+
+	return value, false
+}
+
+func redactString(s string, patterns []*regexp.Regexp) string {
+	for _, pattern := range patterns {
+		s = pattern.ReplaceAllString(s, Redacted)
+	}
+
+	return s
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestRedactFieldsAndPatterns(t *testing.T) {
+	RedactFields("Password", "authorization")
+	RedactPattern(regexp.MustCompile(`\b\d{4}-\d{4}-\d{4}-\d{4}\b`))
+	defer func() { redactedFields, redactPatterns = nil, nil }()
+
+	memory := NewMemoryHandler()
+	log := (NewLogger("", LevelInfo, memory)).WithFields(map[string]interface{}{
+		"password": "hunter2",
+		"user":     "ana",
+		"err":      errors.New("card 4111-1111-1111-1111 declined"),
+	})
+	log.Info("charging 4111-1111-1111-1111")
+
+	entries := memory.Entries()
+	if len(entries) != 1 || entries[0].Msg != "charging [REDACTED]" {
+		t.Fatal("expected the message to be redacted, got", entries)
+	}
+	fields := entries[0].Fields
+	if fields["password"] != Redacted || fields["user"] != "ana" || fields["err"] != "card [REDACTED] declined" {
+		t.Fatal("unexpected fields", fields)
+	}
+	if log.fields["password"] != "hunter2" {
+		t.Fatal("expected the fields of the logger to be left alone")
+	}
+}
+
+func TestRedactPatternMasksErrorChainAndNestedValues(t *testing.T) {
+	RedactFields("token")
+	RedactPattern(regexp.MustCompile(`password=\S+`))
+	defer func() { redactedFields, redactPatterns = nil, nil }()
+
+	memory := NewMemoryHandler()
+	nested := map[string]interface{}{"token": "abc", "dsn": "db password=hunter2"}
+	log := NewLogger("", LevelInfo, memory).WithFields(map[string]interface{}{
+		"conn": nested,
+		"args": []interface{}{"password=hunter2", 3},
+	})
+	err := fmt.Errorf("connect: %w", errors.New("auth failed password=hunter2"))
+	log.ErrorErr(err, "retrying")
+
+	fields := memory.Entries()[0].Fields
+	if fields[ErrorField] != "connect: auth failed [REDACTED]" {
+		t.Fatal("unexpected error", fields[ErrorField])
+	}
+	chain, _ := fields[ErrorChainField].([]string)
+	if len(chain) != 2 || chain[0] != "connect: auth failed [REDACTED]" || chain[1] != "auth failed [REDACTED]" {
+		t.Fatal("expected the error chain to be redacted, got", fields[ErrorChainField])
+	}
+	conn, _ := fields["conn"].(map[string]interface{})
+	if conn["token"] != Redacted || conn["dsn"] != "db [REDACTED]" {
+		t.Fatal("expected the nested map to be redacted, got", fields["conn"])
+	}
+	if args, _ := fields["args"].([]interface{}); len(args) != 2 || args[0] != Redacted || args[1] != 3 {
+		t.Fatal("expected the nested slice to be redacted, got", fields["args"])
+	}
+	if nested["token"] != "abc" || nested["dsn"] != "db password=hunter2" {
+		t.Fatal("expected the fields of the logger to be left alone, got", nested)
+	}
+}
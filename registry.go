@@ -0,0 +1,60 @@
+package logger
+
+import "strings"
+
+// namespaceHandlerResolver it's protected by loggersLock
+var namespaceHandlerResolver func(namespace string) []Interface
+
+// SetNamespaceHandlerResolver set a function consulted by Namespace for the handlers of a new namespace, returning nil
+// falls back to the handlers of its ancestors or a DefaultHandler
+func SetNamespaceHandlerResolver(resolver func(namespace string) []Interface) {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+
+	namespaceHandlerResolver = resolver
+}
+
+// Loggers return the registered loggers sorted by namespace, DefaultLogger included
+func Loggers() []*Logger {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+
+	return sortedLoggers()
+}
+
+// SaveNamespaces snapshot the registered namespaces with their levels and handlers, the returned function goes back to
+// them and forgets the namespaces registered meanwhile. It's meant for tests, see the logtest package
+func SaveNamespaces() (restore func()) {
+	type saved struct {
+		logger   *Logger
+		level    Level
+		explicit bool
+		handlers []Interface
+	}
+
+	loggersLock.Lock()
+	snapshot := make([]saved, 0, len(loggers))
+	for _, logger := range sortedLoggers() {
+		snapshot = append(snapshot, saved{logger, logger.GetLevel(), logger.explicitLevel, logger.Handlers()})
+	}
+	resolver := namespaceHandlerResolver
+	loggersLock.Unlock()
+
+	return func() {
+		loggersLock.Lock()
+		loggers = make(map[string]*Logger, len(snapshot))
+		changes := make([]levelChange, 0, len(snapshot))
+		for _, saved := range snapshot {
+			loggers[strings.ToLower(saved.logger.Namespace)] = saved.logger
+			saved.logger.explicitLevel = saved.explicit
+			changes = append(changes, saved.logger.swapLevel(saved.level))
+		}
+		namespaceHandlerResolver = resolver
+		loggersLock.Unlock()
+
+		for i, saved := range snapshot {
+			saved.logger.SetHandlers(saved.handlers)
+			changes[i].notify()
+		}
+	}
+}
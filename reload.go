@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// ReloadLevels read again the environment variables of every namespace and apply them. A level set by SetLevel is only
+// replaced by the namespace's own variable, not by the ones of its ancestors or the default one, and namespaces
+// without any variable keep their level
+func ReloadLevels() {
+	loggersLock.Lock()
+	var changes []levelChange
+	for _, logger := range sortedLoggers() {
+		if getEnvVarLevel(logger.Namespace) == "" {
+			continue
+		}
+		if logger.explicitLevel && os.Getenv(getEnvVarName(logger.Namespace)) == "" {
+			continue
+		}
+		changes = append(changes, logger.swapLevel(resolveLevel(logger.Namespace)))
+	}
+	loggersLock.Unlock()
+
+	notifyLevels(changes)
+}
+
+// WatchSignal call ReloadLevels whenever sig arrives (usually syscall.SIGHUP), call the returned function to stop
+// watching
+func WatchSignal(sig os.Signal) (stop func()) {
+	return onSignal(sig, ReloadLevels)
+}
+
+const defaultWatchInterval = time.Minute
+
+// WatchInterval call ReloadLevels every interval, e.g. when the environment is refreshed by the platform, call the
+// returned function to stop watching. An interval of 0 or less means the default of a minute
+func WatchInterval(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ReloadLevels()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// onSignal call fn, from a single goroutine, whenever sig arrives
+func onSignal(sig os.Signal, fn func()) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	signal.Notify(signals, sig)
+
+	go func() {
+		defer close(finished)
+
+		for {
+			select {
+			case <-signals:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			<-finished
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"container/list"
+	"sync"
+)
+
+const defaultSampleCacheSize = 1000
+
+type (
+	// FirstThenSampleHandler always forwards the first occurrence of a message, then only 1 of every sampleRate
+	// repeats of it. The messages seen are kept in a LRU cache of size entries, a message evicted from it counts as
+	// new again. Fatal messages are always forwarded
+	FirstThenSampleHandler struct {
+		handler    Interface
+		sampleRate uint64
+		size       int
+
+		lock      sync.Mutex
+		namespace string
+		lru       *list.List
+		seen      map[sampleKey]*list.Element
+		metrics   HandlerMetrics
+	}
+
+	sampleKey struct {
+		level Level
+		msg   string
+	}
+
+	sampleEntry struct {
+		key   sampleKey
+		count uint64
+	}
+)
+
+// NewFirstThenSampleHandler sampleRate below 2 forwards every message, size 0 means the default of 1000 messages
+func NewFirstThenSampleHandler(handler Interface, sampleRate, size int) *FirstThenSampleHandler {
+	if sampleRate < 1 {
+		sampleRate = 1
+	}
+	if size <= 0 {
+		size = defaultSampleCacheSize
+	}
+
+	return &FirstThenSampleHandler{
+		handler:    handler,
+		sampleRate: uint64(sampleRate),
+		size:       size,
+		lru:        list.New(),
+		seen:       map[sampleKey]*list.Element{},
+	}
+}
+
+func (sampler *FirstThenSampleHandler) Init(namespace string, level Level) {
+	sampler.lock.Lock()
+	sampler.namespace = namespace
+	sampler.lock.Unlock()
+
+	if initHandler, ok := sampler.handler.(InitInterface); ok {
+		initHandler.Init(namespace, level)
+	}
+}
+
+func (sampler *FirstThenSampleHandler) Log(record Record) {
+	if sampler.allow(sampleKey{level: record.Level, msg: record.Message}) {
+		dispatchTo(sampler.handler, record)
+	}
+}
+
+func (sampler *FirstThenSampleHandler) Debug(msg string) {
+	sampler.Log(sampler.record(LevelDebug, msg))
+}
+
+func (sampler *FirstThenSampleHandler) Info(msg string) {
+	sampler.Log(sampler.record(LevelInfo, msg))
+}
+
+func (sampler *FirstThenSampleHandler) Warn(msg string) {
+	sampler.Log(sampler.record(LevelWarn, msg))
+}
+
+func (sampler *FirstThenSampleHandler) Error(msg string) {
+	sampler.Log(sampler.record(LevelError, msg))
+}
+
+func (sampler *FirstThenSampleHandler) Fatal(msg string) {
+	if fatalHandler, ok := sampler.handler.(FatalInterface); ok {
+		fatalHandler.Fatal(msg)
+	}
+}
+
+// Metrics ...
+func (sampler *FirstThenSampleHandler) Metrics() HandlerMetrics {
+	sampler.lock.Lock()
+	defer sampler.lock.Unlock()
+
+	return sampler.metrics
+}
+
+func (sampler *FirstThenSampleHandler) record(level Level, msg string) Record {
+	sampler.lock.Lock()
+	defer sampler.lock.Unlock()
+
+	return Record{Level: level, Namespace: sampler.namespace, Message: msg, Time: now()}
+}
+
+func (sampler *FirstThenSampleHandler) allow(key sampleKey) bool {
+	sampler.lock.Lock()
+	defer sampler.lock.Unlock()
+
+	element, ok := sampler.seen[key]
+	if !ok {
+		element = sampler.lru.PushFront(&sampleEntry{key: key})
+		sampler.seen[key] = element
+		if sampler.lru.Len() > sampler.size {
+			oldest := sampler.lru.Back()
+			sampler.lru.Remove(oldest)
+			delete(sampler.seen, oldest.Value.(*sampleEntry).key)
+		}
+	} else {
+		sampler.lru.MoveToFront(element)
+	}
+
+	entry := element.Value.(*sampleEntry)
+	entry.count++
+	// the first occurrence and then every sampleRate repeats
+	if (entry.count-1)%sampler.sampleRate != 0 {
+		sampler.metrics.Dropped++
+		return false
+	}
+
+	sampler.metrics.Emitted++
+	return true
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Sampler decides which messages of a logger are kept, it's called after the level check and before the message
+	// is formatted, with the unformatted message. It must be safe for concurrent use
+	Sampler interface {
+		Allow(level Level, msg string) bool
+	}
+
+	// rateSampler keeps 1 of every N messages of each level
+	rateSampler struct {
+		counts [LevelDebug + 1]uint64
+		every  uint64
+	}
+
+	// Sampling keeps, within each Tick (1s by default), the first Initial messages with the same level and format and
+	// then 1 of every Thereafter of them, Thereafter 0 drops the rest
+	Sampling struct {
+		Initial    int
+		Thereafter int
+		Tick       time.Duration
+	}
+
+	samplingSampler struct {
+		sampling Sampling
+
+		lock   sync.Mutex
+		start  time.Time
+		counts map[sampleKey]int
+	}
+
+	// perSecondSampler keeps up to N messages per second of each level
+	perSecondSampler struct {
+		limiters [LevelDebug + 1]*rateLimiter
+	}
+)
+
+// WithSampler return a copy of the logger that drops the messages sampler doesn't allow, a nil sampler allows
+// everything. Fatal messages are never sampled
+func (logger *Logger) WithSampler(sampler Sampler) *Logger {
+	derived := logger.derive()
+	derived.sampler = sampler
+
+	return derived
+}
+
+// SetSampling sample the messages of logger, and of the loggers derived from it afterwards, a zero Sampling turns
+// sampling off
+func (logger *Logger) SetSampling(sampling Sampling) {
+	var sampler Sampler
+	if sampling != (Sampling{}) {
+		sampler = newSamplingSampler(sampling)
+	}
+
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	logger.sampler = sampler
+}
+
+// Dropped it's the number of messages dropped by sampling and by the rate limit of the namespace, including the ones
+// of the loggers derived from it
+func (logger *Logger) Dropped() uint64 {
+	return logger.counters.dropped()
+}
+
+func (logger *Logger) currentSampler() Sampler {
+	logger.handlersLock.Lock()
+	defer logger.handlersLock.Unlock()
+
+	return logger.sampler
+}
+
+func newSamplingSampler(sampling Sampling) *samplingSampler {
+	if sampling.Tick <= 0 {
+		sampling.Tick = time.Second
+	}
+
+	return &samplingSampler{sampling: sampling, counts: map[sampleKey]int{}}
+}
+
+func (sampler *samplingSampler) Allow(level Level, msg string) bool {
+	sampler.lock.Lock()
+	defer sampler.lock.Unlock()
+
+	if current := time.Now(); current.Sub(sampler.start) >= sampler.sampling.Tick {
+		sampler.start = current
+		sampler.counts = map[sampleKey]int{}
+	}
+
+	key := sampleKey{level: level, msg: msg}
+	sampler.counts[key]++
+	count := sampler.counts[key]
+
+	if count <= sampler.sampling.Initial {
+		return true
+	}
+
+	return sampler.sampling.Thereafter > 0 && (count-sampler.sampling.Initial)%sampler.sampling.Thereafter == 0
+}
+
+// NewRateSampler keep the first message and then 1 of every every messages, counting each level on its own so a
+// flood of debug messages doesn't hide a rare error
+func NewRateSampler(every int) Sampler {
+	if every < 1 {
+		every = 1
+	}
+
+	return &rateSampler{every: uint64(every)}
+}
+
+func (sampler *rateSampler) Allow(level Level, msg string) bool {
+	if level > LevelDebug {
+		return true
+	}
+
+	return (atomic.AddUint64(&sampler.counts[level], 1)-1)%sampler.every == 0
+}
+
+// NewPerSecondSampler keep up to n messages per second of each level, in bursts of up to n
+func NewPerSecondSampler(n int) Sampler {
+	if n < 1 {
+		n = 1
+	}
+
+	sampler := &perSecondSampler{}
+	for level := range sampler.limiters {
+		sampler.limiters[level] = &rateLimiter{
+			limit:  float64(n),
+			period: time.Second,
+			tokens: float64(n),
+			last:   time.Now(),
+		}
+	}
+
+	return sampler
+}
+
+func (sampler *perSecondSampler) Allow(level Level, msg string) bool {
+	if level > LevelDebug {
+		return true
+	}
+
+	return sampler.limiters[level].allow()
+}
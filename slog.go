@@ -0,0 +1,171 @@
+//go:build go1.21
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+type (
+	// slogHandler it's a slog.Handler backed by a Logger
+	slogHandler struct {
+		logger *Logger
+		fields map[string]interface{}
+		group  string
+	}
+
+	// SlogAdapter it's a handler that forwards every record to a slog.Handler, so handlers written for log/slog can
+	// be added to a Logger
+	SlogAdapter struct {
+		handler slog.Handler
+
+		lock      sync.Mutex
+		namespace string
+	}
+)
+
+// NewSlogHandler return a slog.Handler that logs with logger, so libraries taking a *slog.Logger end up in the same
+// handlers. Attributes become fields, grouped attributes are named "group.key", and the slog levels are mapped to the
+// nearest level below them (e.g. slog.LevelWarn+2 it's warn)
+func NewSlogHandler(logger *Logger) slog.Handler {
+	return &slogHandler{logger: logger}
+}
+
+func (handler *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return levelFromSlog(level) <= handler.logger.levelFor(ctx)
+}
+
+func (handler *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(map[string]interface{}, len(handler.fields)+record.NumAttrs())
+	for key, value := range handler.fields {
+		fields[key] = value
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(fields, handler.group, attr)
+		return true
+	})
+
+	logger := handler.logger.withContext(ctx)
+	if len(fields) > 0 {
+		logger = logger.WithFields(fields)
+	}
+	logger.log(handler.logger.levelFor(ctx), levelFromSlog(record.Level), "%s", record.Message)
+
+	return nil
+}
+
+func (handler *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(map[string]interface{}, len(handler.fields)+len(attrs))
+	for key, value := range handler.fields {
+		fields[key] = value
+	}
+	for _, attr := range attrs {
+		addSlogAttr(fields, handler.group, attr)
+	}
+
+	return &slogHandler{logger: handler.logger, fields: fields, group: handler.group}
+}
+
+func (handler *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+
+	return &slogHandler{logger: handler.logger, fields: handler.fields, group: handler.group + name + "."}
+}
+
+// addSlogAttr add attr to fields, flattening groups into "group.key"
+func addSlogAttr(fields map[string]interface{}, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, groupAttr := range value.Group() {
+			addSlogAttr(fields, prefix, groupAttr)
+		}
+		return
+	}
+
+	if attr.Key == "" {
+		return
+	}
+	fields[prefix+attr.Key] = value.Any()
+}
+
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	}
+
+	return LevelError
+}
+
+// NewSlogAdapter return a handler forwarding to handler, the namespace is sent as the "namespace" attribute and the
+// fields as attributes sorted by key
+func NewSlogAdapter(handler slog.Handler) *SlogAdapter {
+	return &SlogAdapter{handler: handler}
+}
+
+func (adapter *SlogAdapter) Init(namespace string, level Level) {
+	adapter.lock.Lock()
+	defer adapter.lock.Unlock()
+
+	adapter.namespace = namespace
+}
+
+func (adapter *SlogAdapter) Log(record Record) {
+	var level slog.Level
+	switch record.Level {
+	case LevelDebug:
+		level = slog.LevelDebug
+	case LevelInfo:
+		level = slog.LevelInfo
+	case LevelWarn:
+		level = slog.LevelWarn
+	default:
+		level = slog.LevelError
+	}
+
+	adapter.handle(level, record)
+}
+
+// FatalFields it's sent at slog.LevelError+4
+func (adapter *SlogAdapter) FatalFields(msg string, fields map[string]interface{}) {
+	adapter.lock.Lock()
+	namespace := adapter.namespace
+	adapter.lock.Unlock()
+
+	adapter.handle(slog.LevelError+4, Record{Namespace: namespace, Message: msg, Time: now(), Fields: fields})
+}
+
+func (adapter *SlogAdapter) handle(level slog.Level, record Record) {
+	ctx := context.Background()
+	if !adapter.handler.Enabled(ctx, level) {
+		return
+	}
+
+	slogRecord := slog.NewRecord(record.Time, level, record.Message, 0)
+	if record.Namespace != "" {
+		slogRecord.AddAttrs(slog.String("namespace", record.Namespace))
+	}
+
+	keys := make([]string, 0, len(record.Fields))
+	for key := range record.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		slogRecord.AddAttrs(slog.Any(key, record.Fields[key]))
+	}
+
+	adapter.handler.Handle(ctx, slogRecord)
+}
@@ -0,0 +1,42 @@
+//go:build go1.21
+
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandlerLogsWithLogger(t *testing.T) {
+	memory := NewMemoryHandler()
+	log := NewLogger("slog", LevelInfo, memory)
+	slogger := slog.New(NewSlogHandler(log)).With("service", "api").WithGroup("req")
+
+	slogger.Debug("hidden")
+	slogger.Warn("slow request", "ms", 1200, slog.Group("user", "id", 7))
+
+	entries := memory.Entries()
+	if len(entries) != 1 || entries[0].Level != LevelWarn || entries[0].Msg != "slow request" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	fields := entries[0].Fields
+	if fields["service"] != "api" || fields["req.ms"] != int64(1200) || fields["req.user.id"] != int64(7) {
+		t.Fatal("unexpected fields", fields)
+	}
+}
+
+func TestSlogAdapterForwardsRecords(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger("adapter", LevelDebug)
+	log.AddHandler(NewSlogAdapter(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	log.Debug("filtered by the slog handler")
+	log.With("status", 200).Info("request done")
+
+	out := buf.String()
+	if strings.Contains(out, "filtered") || !strings.Contains(out, `level=INFO msg="request done" namespace=adapter status=200`) {
+		t.Fatal("unexpected output", out)
+	}
+}
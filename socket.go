@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSocketBufferSize = 1000
+	socketDialTimeout       = time.Second
+	socketRetryInterval     = time.Second
+	socketWriteTimeout      = time.Second
+)
+
+// SocketHandler writes newline-delimited records to a unix, tcp or udp socket, e.g. a local log agent. The connection
+// is dialed in the background on the first record and, when it fails, again on the next records, at most once per
+// second. Up to BufferSize lines are kept meanwhile, dropping the oldest ones
+type SocketHandler struct {
+	// BufferSize defaults to 1000 lines
+	BufferSize int
+
+	network   string
+	address   string
+	namespace string
+
+	lock     sync.Mutex
+	conn     net.Conn
+	lastDial time.Time
+	dialing  bool
+	dials    sync.WaitGroup
+	pending  []string
+	metrics  HandlerMetrics
+	closed   bool
+}
+
+// NewSocketHandler network is "unix", "tcp" or "udp", as accepted by net.Dial
+func NewSocketHandler(network, address string) *SocketHandler {
+	return &SocketHandler{network: network, address: address}
+}
+
+func (handler *SocketHandler) Init(namespace string, level Level) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	if namespace != "" {
+		namespace = "<" + namespace + "> "
+	}
+	handler.namespace = namespace
+}
+
+func (handler *SocketHandler) Debug(msg string) {
+	handler.write("[DEBUG] ", msg)
+}
+
+func (handler *SocketHandler) Info(msg string) {
+	handler.write("[INFO] ", msg)
+}
+
+func (handler *SocketHandler) Warn(msg string) {
+	handler.write("[WARN] ", msg)
+}
+
+func (handler *SocketHandler) Error(msg string) {
+	handler.write("[ERROR] ", msg)
+}
+
+func (handler *SocketHandler) Fatal(msg string) {
+	handler.write("[FATAL] ", msg)
+}
+
+// Metrics ...
+func (handler *SocketHandler) Metrics() HandlerMetrics {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	return handler.metrics
+}
+
+// Close waits for a dial in progress and closes the connection
+func (handler *SocketHandler) Close() error {
+	handler.lock.Lock()
+	handler.closed = true
+	handler.lock.Unlock()
+
+	handler.dials.Wait()
+
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	if handler.conn == nil {
+		return nil
+	}
+
+	err := handler.conn.Close()
+	handler.conn = nil
+
+	return err
+}
+
+func (handler *SocketHandler) write(label, msg string) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	if handler.closed {
+		handler.metrics.Dropped++
+		return
+	}
+
+	handler.buffer(handler.namespace + label + msg + "\n")
+
+	if handler.conn == nil {
+		if !handler.dialing && time.Since(handler.lastDial) >= socketRetryInterval {
+			handler.dialing = true
+			handler.lastDial = time.Now()
+			handler.dials.Add(1)
+			go handler.dial()
+		}
+		return
+	}
+
+	handler.flush()
+}
+
+// flush writes the pending lines, it must be called with the lock held
+func (handler *SocketHandler) flush() {
+	handler.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+	for len(handler.pending) > 0 {
+		if _, err := handler.conn.Write([]byte(handler.pending[0])); err != nil {
+			handler.metrics.Errors++
+			handler.conn.Close()
+			handler.conn = nil
+			return
+		}
+		handler.pending = handler.pending[1:]
+		handler.metrics.Emitted++
+	}
+}
+
+func (handler *SocketHandler) buffer(line string) {
+	size := handler.BufferSize
+	if size <= 0 {
+		size = defaultSocketBufferSize
+	}
+
+	if len(handler.pending) >= size {
+		handler.pending = handler.pending[1:]
+		handler.metrics.Dropped++
+	}
+	handler.pending = append(handler.pending, line)
+}
+
+// dial runs in its own goroutine, so the records aren't held by a slow connection, and flushes the lines kept meanwhile
+func (handler *SocketHandler) dial() {
+	defer handler.dials.Done()
+
+	conn, err := net.DialTimeout(handler.network, handler.address, socketDialTimeout)
+
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	handler.dialing = false
+	if err != nil {
+		handler.metrics.Errors++
+		return
+	}
+	if handler.closed {
+		conn.Close()
+		return
+	}
+
+	handler.conn = conn
+	handler.flush()
+}
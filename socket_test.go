@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSocketHandlerReconnectsAndFlushesPending(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	handler := NewSocketHandler("tcp", address)
+	handler.Init("socket", LevelInfo)
+	defer handler.Close()
+
+	handler.Info("while down")
+	handler.dials.Wait()
+	if metrics := handler.Metrics(); metrics.Emitted != 0 || metrics.Errors == 0 {
+		t.Fatal("expected the dial to fail, got", metrics)
+	}
+
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	handler.lock.Lock()
+	handler.lastDial = time.Time{}
+	handler.lock.Unlock()
+	handler.Info("back up")
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for _, expected := range []string{"<socket> [INFO] while down\n", "<socket> [INFO] back up\n"} {
+		line, err := reader.ReadString('\n')
+		if err != nil || line != expected {
+			t.Fatalf("expected %q, got %q (%v)", expected, line, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// NamespaceStats it's the activity of a logger since it was created
+type NamespaceStats struct {
+	Namespace string
+	Level     Level
+	// Emitted and Suppressed count the messages per level that passed the level check and the ones discarded by it,
+	// Suppressed growing while Emitted doesn't tells a level is hiding everything
+	Emitted    map[Level]uint64
+	Suppressed map[Level]uint64
+	// Dropped it's the messages dropped by sampling, the rate limit and SetAsync
+	Dropped uint64
+	// Handlers it's the sum of the metrics of the handlers, see CollectMetrics
+	Handlers HandlerMetrics
+}
+
+// Stats return the activity of logger, including the messages of the loggers derived from it (e.g. by With)
+func (logger *Logger) Stats() NamespaceStats {
+	stats := NamespaceStats{
+		Namespace:  logger.Namespace,
+		Level:      logger.GetLevel(),
+		Emitted:    make(map[Level]uint64, LevelDebug),
+		Suppressed: make(map[Level]uint64, LevelDebug),
+		Handlers:   logger.CollectMetrics(),
+	}
+	for level := LevelFatal; level <= LevelDebug; level++ {
+		stats.Emitted[level] = logger.counters.emitted(level)
+		if level != LevelFatal {
+			stats.Suppressed[level] = logger.counters.suppressed(level)
+		}
+	}
+
+	stats.Dropped = logger.Dropped()
+	if async := logger.asyncHandler(); async != nil {
+		stats.Dropped += async.Dropped()
+	}
+
+	return stats
+}
+
+// Stats return the activity of every namespace, sorted by namespace
+func Stats() []NamespaceStats {
+	loggersLock.Lock()
+	sorted := sortedLoggers()
+	stats := make([]NamespaceStats, 0, len(sorted))
+	for _, logger := range sorted {
+		stats = append(stats, logger.Stats())
+	}
+	loggersLock.Unlock()
+
+	return stats
+}
+
+// PublishExpvar publish Stats as the expvar variable name, served as JSON at /debug/vars. Like expvar.Publish it
+// panics when name is already in use
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return Stats()
+	}))
+}
+
+// counters it's the activity counted by Stats and Heartbeat, shared by a logger and the loggers derived from it. The
+// methods do nothing on nil counters, of a Logger that wasn't created by Namespace or NewLogger
+type counters struct {
+	emittedCounts    [LevelDebug + 1]uint64
+	suppressedCounts [LevelDebug + 1]uint64
+	droppedCount     uint64
+}
+
+func (c *counters) emit(level Level) {
+	if c != nil {
+		atomic.AddUint64(&c.emittedCounts[level], 1)
+	}
+}
+
+func (c *counters) suppress(level Level) {
+	if c != nil {
+		atomic.AddUint64(&c.suppressedCounts[level], 1)
+	}
+}
+
+func (c *counters) drop() {
+	if c != nil {
+		atomic.AddUint64(&c.droppedCount, 1)
+	}
+}
+
+func (c *counters) emitted(level Level) uint64 {
+	if c == nil {
+		return 0
+	}
+
+	return atomic.LoadUint64(&c.emittedCounts[level])
+}
+
+func (c *counters) suppressed(level Level) uint64 {
+	if c == nil {
+		return 0
+	}
+
+	return atomic.LoadUint64(&c.suppressedCounts[level])
+}
+
+func (c *counters) dropped() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	return atomic.LoadUint64(&c.droppedCount)
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syslogTimeFormat it's RFC 5424 TIMESTAMP, that allows up to microseconds
+const syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// localSyslogSockets are tried in order when no address is given
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogHandler sends RFC 5424 messages to a local or remote syslog, using the namespace as APP-NAME (the program name
+// for the default namespace) and the fields in the message like DefaultHandler. Levels map to the severities debug,
+// info, warning, err and crit for fatal. When sending fails it reconnects once and retries the message
+type SyslogHandler struct {
+	// Facility defaults to 1 (user-level messages)
+	Facility int
+	// Hostname defaults to os.Hostname()
+	Hostname string
+
+	network string
+	address string
+
+	lock      sync.Mutex
+	conn      net.Conn
+	namespace string
+	metrics   HandlerMetrics
+}
+
+// NewSyslogHandler connect to a syslog, network is "udp", "tcp", "unix" or "unixgram" as accepted by net.Dial, an
+// empty network and address use the local syslog socket
+func NewSyslogHandler(network, address string) (*SyslogHandler, error) {
+	hostname, _ := os.Hostname()
+	handler := &SyslogHandler{Facility: 1, Hostname: hostname, network: network, address: address}
+
+	if err := handler.dial(); err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+func (handler *SyslogHandler) Init(namespace string, level Level) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	handler.namespace = namespace
+}
+
+func (handler *SyslogHandler) Log(record Record) {
+	var severity int
+	switch record.Level {
+	case LevelDebug:
+		severity = 7
+	case LevelInfo:
+		severity = 6
+	case LevelWarn:
+		severity = 4
+	default:
+		severity = 3
+	}
+
+	handler.send(severity, record.Namespace, record.Time, record.Message+formatFields(record.Fields))
+}
+
+func (handler *SyslogHandler) FatalFields(msg string, fields map[string]interface{}) {
+	handler.lock.Lock()
+	namespace := handler.namespace
+	handler.lock.Unlock()
+
+	handler.send(2, namespace, now(), msg+formatFields(fields))
+}
+
+// Metrics ...
+func (handler *SyslogHandler) Metrics() HandlerMetrics {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	return handler.metrics
+}
+
+// Close ...
+func (handler *SyslogHandler) Close() error {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	if handler.conn == nil {
+		return nil
+	}
+
+	err := handler.conn.Close()
+	handler.conn = nil
+
+	return err
+}
+
+func (handler *SyslogHandler) send(severity int, namespace string, t time.Time, msg string) {
+	appName := namespace
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+
+	line := fmt.Sprintf("<%d>1 %s %s %s %d - - %s", handler.Facility*8+severity, t.Format(syslogTimeFormat),
+		syslogHeaderField(handler.Hostname, 255), syslogHeaderField(appName, 48), os.Getpid(), msg)
+
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	// retry once on a fresh connection, e.g. after the syslog restarted
+	for attempt := 0; attempt < 2; attempt++ {
+		if handler.conn == nil {
+			if err := handler.dial(); err != nil {
+				break
+			}
+		}
+
+		if _, err := handler.conn.Write(handler.frame(line)); err == nil {
+			handler.metrics.Emitted++
+			return
+		}
+		handler.conn.Close()
+		handler.conn = nil
+	}
+
+	handler.metrics.Errors++
+	handler.metrics.Dropped++
+}
+
+// frame stream transports use octet counting (RFC 6587), datagrams carry a single message
+func (handler *SyslogHandler) frame(line string) []byte {
+	switch handler.conn.LocalAddr().Network() {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return []byte(fmt.Sprintf("%d %s", len(line), line))
+	}
+
+	return []byte(line)
+}
+
+func (handler *SyslogHandler) dial() error {
+	if handler.network != "" || handler.address != "" {
+		conn, err := net.DialTimeout(handler.network, handler.address, socketDialTimeout)
+		if err != nil {
+			return err
+		}
+		handler.conn = conn
+		return nil
+	}
+
+	for _, path := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.DialTimeout(network, path, socketDialTimeout); err == nil {
+				handler.conn = conn
+				return nil
+			}
+		}
+	}
+
+	return errors.New("logger: no local syslog socket found")
+}
+
+// syslogHeaderField header fields are printable US-ASCII without spaces, "-" when empty
+func syslogHeaderField(value string, max int) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+
+	if value == "" {
+		return "-"
+	}
+	if len(value) > max {
+		value = value[:max]
+	}
+
+	return value
+}
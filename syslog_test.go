@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogHandlerSendsRFC5424(t *testing.T) {
+	TestMode = true
+	defer func() { TestMode = false }()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	handler, err := NewSyslogHandler("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+	handler.Hostname = "web 1"
+
+	log := NewLogger("billing", LevelInfo)
+	log.AddHandler(handler)
+	log.With("invoice", 42).Error("charge failed")
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<11>1 2000-01-01T00:00:00.000000Z web_1 billing ") ||
+		!strings.HasSuffix(msg, " - - charge failed invoice=42") {
+		t.Fatal("unexpected message", msg)
+	}
+}
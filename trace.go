@@ -0,0 +1,40 @@
+package logger
+
+import "context"
+
+const (
+	// TraceIDField it's the field holding the trace ID found by a trace extractor
+	TraceIDField = "trace_id"
+	// SpanIDField it's the field holding the span ID found by a trace extractor
+	SpanIDField = "span_id"
+)
+
+// RegisterTraceExtractor attach the trace and span IDs returned by extractor as the trace_id and span_id fields of
+// the Ctx log methods and WithContext, empty IDs are left out. It keeps tracing libraries out of this package, with
+// OpenTelemetry it looks like:
+//
+//	logger.RegisterTraceExtractor(func(ctx context.Context) (string, string) {
+//		spanContext := trace.SpanContextFromContext(ctx)
+//		if !spanContext.IsValid() {
+//			return "", ""
+//		}
+//		return spanContext.TraceID().String(), spanContext.SpanID().String()
+//	})
+func RegisterTraceExtractor(extractor func(ctx context.Context) (traceID, spanID string)) {
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		traceID, spanID := extractor(ctx)
+		if traceID == "" && spanID == "" {
+			return nil
+		}
+
+		fields := make(map[string]interface{}, 2)
+		if traceID != "" {
+			fields[TraceIDField] = traceID
+		}
+		if spanID != "" {
+			fields[SpanIDField] = spanID
+		}
+
+		return fields
+	})
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// CountingWriter it's an io.Writer that counts the bytes written to the wrapped Writer, handlers can use it to
+// implement BytesCounterInterface
+type CountingWriter struct {
+	// count is first to keep it 64-bit aligned for atomic operations
+	count  uint64
+	Writer io.Writer
+}
+
+// NewCountingWriter ...
+func NewCountingWriter(w io.Writer) *CountingWriter {
+	return &CountingWriter{Writer: w}
+}
+
+// Write ...
+func (w *CountingWriter) Write(b []byte) (int, error) {
+	n, err := w.Writer.Write(b)
+	atomic.AddUint64(&w.count, uint64(n))
+	return n, err
+}
+
+// BytesWritten ...
+func (w *CountingWriter) BytesWritten() uint64 {
+	return atomic.LoadUint64(&w.count)
+}
+
+// ResetBytes ...
+func (w *CountingWriter) ResetBytes() {
+	atomic.StoreUint64(&w.count, 0)
+}
+
+// lockedWriter serializes the writes of everyone sharing the same lock, adding the bytes written to count
+type lockedWriter struct {
+	lock   *sync.Mutex
+	count  *uint64
+	writer io.Writer
+}
+
+func (w *lockedWriter) Write(b []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	n, err := w.writer.Write(b)
+	atomic.AddUint64(w.count, uint64(n))
+	return n, err
+}
+
+// levelWriter logs each Write as a message at level
+type levelWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w *levelWriter) Write(b []byte) (int, error) {
+	w.logger.log(w.logger.GetLevel(), w.level, "%s", strings.TrimRight(string(b), "\n"))
+	return len(b), nil
+}
+
+// Writer return an io.Writer logging each Write as a message at level, with the trailing newlines trimmed. Writers
+// never exit, LevelFatal and LevelNone log at error and levels above LevelDebug at debug
+func (logger *Logger) Writer(level Level) io.Writer {
+	if level < LevelError {
+		level = LevelError
+	} else if level > LevelDebug {
+		level = LevelDebug
+	}
+
+	return &levelWriter{logger: logger, level: level}
+}
+
+// StdLogger return a *log.Logger writing to logger at level, for libraries that take one like http.Server.ErrorLog
+func (logger *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(logger.Writer(level), "", 0)
+}